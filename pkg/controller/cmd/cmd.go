@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/m-mizutani/ghaudit/pkg/domain/model"
 	"github.com/m-mizutani/ghaudit/pkg/domain/types"
@@ -20,6 +24,8 @@ import (
 func Run(argv []string) error {
 	cfg := &model.Config{}
 	var headers cli.StringSlice
+	var files cli.StringSlice
+	var propertyFilters cli.StringSlice
 	app := &cli.App{
 		Name:  "ghaudit",
 		Usage: "GitHub Audit with OPA/Rego",
@@ -73,6 +79,20 @@ func Run(argv []string) error {
 				Destination: &cfg.Package,
 				Value:       "github.repo",
 			},
+			&cli.StringFlag{
+				Name:        "org-package",
+				EnvVars:     []string{types.EnvOrgPackage},
+				Usage:       "Inquiry policy package name for organization audit",
+				Destination: &cfg.OrgPackage,
+				Value:       "github.org",
+			},
+			&cli.StringFlag{
+				Name:        "aggregate-package",
+				EnvVars:     []string{types.EnvAggregatePackage},
+				Usage:       "Inquiry policy package name for aggregate audit of all repositories",
+				Destination: &cfg.AggregatePackage,
+				Value:       "github.aggregate",
+			},
 			&cli.StringFlag{
 				Name:        "url",
 				Aliases:     []string{"u"},
@@ -80,6 +100,18 @@ func Run(argv []string) error {
 				Usage:       "OPA server URL",
 				Destination: &cfg.URL,
 			},
+			&cli.StringFlag{
+				Name:        "org-url",
+				EnvVars:     []string{types.EnvOrgURL},
+				Usage:       "OPA server URL for organization audit",
+				Destination: &cfg.OrgURL,
+			},
+			&cli.StringFlag{
+				Name:        "aggregate-url",
+				EnvVars:     []string{types.EnvAggregateURL},
+				Usage:       "OPA server URL for aggregate audit of all repositories",
+				Destination: &cfg.AggregateURL,
+			},
 			&cli.StringSliceFlag{
 				Name:        "header",
 				Aliases:     []string{"H"},
@@ -111,21 +143,119 @@ func Run(argv []string) error {
 				EnvVars:     []string{types.EnvFail},
 				Destination: &cfg.Fail,
 			},
+			&cli.BoolFlag{
+				Name:        "collect-all",
+				Usage:       "Retrieve all repository data even if local policy does not refer it",
+				EnvVars:     []string{types.EnvCollectAll},
+				Destination: &cfg.CollectAll,
+			},
+			&cli.BoolFlag{
+				Name:        "graphql",
+				Usage:       "Retrieve branches, branch protections, collaborators and teams by batched GraphQL queries",
+				EnvVars:     []string{types.EnvGraphQL},
+				Destination: &cfg.GraphQL,
+			},
 			&cli.BoolFlag{
 				Name:        "skip-archived",
 				Usage:       "Skip archived repository",
 				EnvVars:     []string{types.EnvSkipArchived},
 				Destination: &cfg.SkipArchived,
 			},
+			&cli.StringSliceFlag{
+				Name:        "property-filter",
+				Usage:       "Audit only repositories having custom property value in name=value format (e.g. tier=critical)",
+				EnvVars:     []string{types.EnvPropertyFilter},
+				Destination: &propertyFilters,
+			},
+
+			// Input options
+			&cli.StringSliceFlag{
+				Name:        "file",
+				Aliases:     []string{"F"},
+				Usage:       "File path in repository to be retrieved into input.files (e.g. CODEOWNERS)",
+				EnvVars:     []string{types.EnvFile},
+				Destination: &files,
+			},
+			&cli.BoolFlag{
+				Name:        "file-content",
+				Usage:       "Retrieve decoded content of files specified by --file",
+				EnvVars:     []string{types.EnvFileContent},
+				Destination: &cfg.FileContent,
+			},
+			&cli.Int64Flag{
+				Name:        "commits",
+				Usage:       "Number of recent commits in default branch to check signature (0 disables)",
+				EnvVars:     []string{types.EnvCommits},
+				Destination: &cfg.Commits,
+				Value:       100,
+			},
 
 			// Runtime options
 			&cli.Int64Flag{
 				Name:        "thread",
-				Usage:       "Thread num",
+				Usage:       "Initial number of concurrent GitHub API requests. It is adjusted by API health",
 				EnvVars:     []string{types.EnvThread},
 				Destination: &cfg.Thread,
 				Value:       1,
 			},
+			&cli.Int64Flag{
+				Name:        "concurrency",
+				Usage:       "Number of repositories retrieved concurrently",
+				EnvVars:     []string{types.EnvConcurrency},
+				Destination: &cfg.Concurrency,
+				Value:       4,
+			},
+			&cli.Int64Flag{
+				Name:        "max-concurrency",
+				Usage:       "Maximum number of concurrent GitHub API requests",
+				EnvVars:     []string{types.EnvMaxConcurrency},
+				Destination: &cfg.MaxConcurrency,
+				Value:       16,
+			},
+			&cli.StringFlag{
+				Name:        "cache-dir",
+				Usage:       "Directory to cache GitHub API responses. Cached responses are revalidated by ETag",
+				EnvVars:     []string{types.EnvCacheDir},
+				Destination: &cfg.CacheDir,
+			},
+			&cli.DurationFlag{
+				Name:        "cache-ttl",
+				Usage:       "Discard cached response not validated for the duration (0 keeps forever)",
+				EnvVars:     []string{types.EnvCacheTTL},
+				Destination: &cfg.CacheTTL,
+				Value:       7 * 24 * time.Hour,
+			},
+			&cli.StringFlag{
+				Name:        "state-dir",
+				Usage:       "Directory to save audit state. Content data (ghaudit.yml, dependencies, files and CODEOWNERS) of repositories not pushed or updated since the last audit is reused, and other data is retrieved every audit",
+				EnvVars:     []string{types.EnvStateDir},
+				Destination: &cfg.StateDir,
+			},
+			&cli.BoolFlag{
+				Name:        "full-refresh",
+				Usage:       "Retrieve content data of all repositories even if audit state is available",
+				EnvVars:     []string{types.EnvFullRefresh},
+				Destination: &cfg.FullRefresh,
+			},
+			&cli.DurationFlag{
+				Name:        "refresh-age",
+				Usage:       "Retrieve content data of all repositories if the last full refresh is older than the duration (0 never)",
+				EnvVars:     []string{types.EnvRefreshAge},
+				Destination: &cfg.RefreshAge,
+				Value:       7 * 24 * time.Hour,
+			},
+			&cli.StringFlag{
+				Name:        "work-dir",
+				Usage:       "Directory to save retrieved data and results progressively as checkpoint",
+				EnvVars:     []string{types.EnvWorkDir},
+				Destination: &cfg.WorkDir,
+			},
+			&cli.BoolFlag{
+				Name:        "resume",
+				Usage:       "Resume interrupted audit from checkpoint in work dir",
+				EnvVars:     []string{types.EnvResume},
+				Destination: &cfg.Resume,
+			},
 			&cli.Int64Flag{
 				Name:        "limit",
 				Usage:       "Limit of auditing repository",
@@ -156,6 +286,8 @@ func Run(argv []string) error {
 		},
 		Before: func(c *cli.Context) error {
 			cfg.Headers = headers.Value()
+			cfg.Files = files.Value()
+			cfg.PropertyFilters = propertyFilters.Value()
 			if err := utils.RenewLogger(cfg.LogLevel, cfg.LogFormat); err != nil {
 				return err
 			}
@@ -216,7 +348,18 @@ func action(cfg *model.Config) func(c *cli.Context) error {
 				privateKey = raw
 			}
 
-			app, err := githubapp.New(cfg.AppID, cfg.InstallID, privateKey)
+			newClient := githubapp.New
+			if cfg.GraphQL {
+				newClient = githubapp.NewGraphQL
+			}
+			limiter := githubapp.NewAdaptiveLimiter(int(cfg.Thread), int(cfg.MaxRequests()))
+			clientOptions := []githubapp.Option{
+				githubapp.WithTransport(githubapp.WithLimiter(limiter)),
+			}
+			if cfg.CacheDir != "" {
+				clientOptions = append(clientOptions, githubapp.WithCache(cfg.CacheDir, cfg.CacheTTL))
+			}
+			app, err := newClient(cfg.AppID, cfg.InstallID, privateKey, clientOptions...)
 			if err != nil {
 				return goerr.Wrap(err).With("appID", cfg.AppID).With("installID", cfg.InstallID)
 			}
@@ -229,7 +372,8 @@ func action(cfg *model.Config) func(c *cli.Context) error {
 			ghapp = loader
 		}
 
-		var policyClient opac.Client
+		var ucOptions []usecase.Option
+		var policyClient, orgPolicyClient, aggregatePolicyClient opac.Client
 		if cfg.Policy != "" {
 			utils.Logger.With("policy", cfg.Policy).Info("Use local policy file(s)")
 			p, err := opac.NewLocal(opac.WithDir(cfg.Policy), opac.WithPackage(cfg.Package))
@@ -237,6 +381,44 @@ func action(cfg *model.Config) func(c *cli.Context) error {
 				return err
 			}
 			policyClient = p
+
+			modules, err := loadPolicyModules(cfg.Policy)
+			if err != nil {
+				return err
+			}
+
+			// organization data is retrieved only if policies require it
+			hasOrgPolicy, err := model.HasPackage(modules, cfg.OrgPackage)
+			if err != nil {
+				return err
+			}
+			if hasOrgPolicy {
+				orgPolicy, err := opac.NewLocal(opac.WithDir(cfg.Policy), opac.WithPackage(cfg.OrgPackage))
+				if err != nil {
+					return err
+				}
+				orgPolicyClient = orgPolicy
+			}
+
+			hasAggregatePolicy, err := model.HasPackage(modules, cfg.AggregatePackage)
+			if err != nil {
+				return err
+			}
+			if hasAggregatePolicy {
+				aggregatePolicy, err := opac.NewLocal(opac.WithDir(cfg.Policy), opac.WithPackage(cfg.AggregatePackage))
+				if err != nil {
+					return err
+				}
+				aggregatePolicyClient = aggregatePolicy
+			}
+
+			if !cfg.CollectAll {
+				fields, err := policyInputFields(cfg, modules)
+				if err != nil {
+					return err
+				}
+				ucOptions = append(ucOptions, usecase.WithInputFields(fields))
+			}
 		} else if cfg.URL != "" {
 			utils.Logger.With("url", cfg.URL).Info("Use local policy file(s)")
 			httpClient, err := newHTTPClient(cfg.Headers)
@@ -248,26 +430,74 @@ func action(cfg *model.Config) func(c *cli.Context) error {
 				return err
 			}
 			policyClient = p
+
+			if cfg.OrgURL != "" {
+				orgPolicy, err := opac.NewRemote(cfg.OrgURL, opac.WithHTTPClient(httpClient))
+				if err != nil {
+					return err
+				}
+				orgPolicyClient = orgPolicy
+			}
+			if cfg.AggregateURL != "" {
+				aggregatePolicy, err := opac.NewRemote(cfg.AggregateURL, opac.WithHTTPClient(httpClient))
+				if err != nil {
+					return err
+				}
+				aggregatePolicyClient = aggregatePolicy
+			}
 		}
 
 		infraOptions := []infra.Option{
 			infra.WithGitHubApp(ghapp),
 			infra.WithPolicy(policyClient),
 		}
+		if orgPolicyClient != nil {
+			infraOptions = append(infraOptions, infra.WithOrgPolicy(orgPolicyClient))
+		}
+		if aggregatePolicyClient != nil {
+			infraOptions = append(infraOptions, infra.WithAggregatePolicy(aggregatePolicyClient))
+		}
 		if cfg.SlackWebhook != "" {
 			infraOptions = append(infraOptions, infra.WithSlack(notify.NewSlackWebhook(cfg.SlackWebhook)))
 		}
 
 		clients := infra.New(infraOptions...)
 
-		ucOptions := []usecase.Option{
+		ucOptions = append(ucOptions,
 			usecase.WithLimit(cfg.Limit),
-			usecase.WithThread(cfg.Thread),
+			usecase.WithConcurrency(cfg.Workers()),
 			usecase.WithSkipArchived(cfg.SkipArchived),
-		}
+			usecase.WithFiles(cfg.Files),
+			usecase.WithFileContent(cfg.FileContent),
+			usecase.WithCommits(cfg.Commits),
+		)
 		if cfg.DumpDir != "" {
 			ucOptions = append(ucOptions, usecase.WithDump(cfg.DumpDir))
 		}
+		if cfg.StateDir != "" {
+			ucOptions = append(ucOptions,
+				usecase.WithStateDir(cfg.StateDir),
+				usecase.WithFullRefresh(cfg.FullRefresh),
+				usecase.WithRefreshAge(cfg.RefreshAge),
+			)
+		}
+		if cfg.WorkDir != "" {
+			ucOptions = append(ucOptions,
+				usecase.WithWorkDir(cfg.WorkDir),
+				usecase.WithResume(cfg.Resume),
+			)
+		}
+		if len(cfg.PropertyFilters) > 0 {
+			var filters []*model.PropertyFilter
+			for _, s := range cfg.PropertyFilters {
+				filter, err := model.ParsePropertyFilter(s)
+				if err != nil {
+					return err
+				}
+				filters = append(filters, filter)
+			}
+			ucOptions = append(ucOptions, usecase.WithPropertyFilters(filters))
+		}
 
 		uc := usecase.New(clients, ucOptions...)
 
@@ -280,3 +510,43 @@ func action(cfg *model.Config) func(c *cli.Context) error {
 
 	}
 }
+
+// loadPolicyModules returns source of local policy files. Test files (*_test.rego) are ignored because they do not affect audit result.
+func loadPolicyModules(dir string) (map[string]string, error) {
+	modules := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".rego" || strings.HasSuffix(path, "_test.rego") {
+			return nil
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		modules[path] = string(raw)
+		return nil
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err).With("policy", dir)
+	}
+	return modules, nil
+}
+
+// policyInputFields returns fields of repository data referred by local policy modules.
+func policyInputFields(cfg *model.Config, modules map[string]string) (model.InputFields, error) {
+	fields, err := model.NewInputFields(modules, cfg.AggregatePackage, cfg.OrgPackage)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields.All() {
+		utils.Logger.Debug("policy requires all repository data")
+	} else {
+		utils.Logger.With("fields", fields.Names()).Debug("policy requires only referred repository data")
+	}
+
+	return fields, nil
+}
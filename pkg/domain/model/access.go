@@ -0,0 +1,309 @@
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v42/github"
+)
+
+const (
+	AccessTypeUser = "user"
+	AccessTypeTeam = "team"
+)
+
+// Grant paths of repository access
+const (
+	AccessViaDirect     = "direct"
+	AccessViaTeam       = "team"
+	AccessViaParentTeam = "parent_team"
+	AccessViaOrgBase    = "org_base"
+	AccessViaOrgOwner   = "org_owner"
+	AccessViaUnknown    = "unknown"
+)
+
+// Normalized repository permissions. Org base permission and team permission are also converted to them.
+const (
+	PermissionNone     = "none"
+	PermissionRead     = "read"
+	PermissionTriage   = "triage"
+	PermissionWrite    = "write"
+	PermissionMaintain = "maintain"
+	PermissionAdmin    = "admin"
+)
+
+var permissionLevel = map[string]int{
+	PermissionNone:     0,
+	PermissionRead:     1,
+	PermissionTriage:   2,
+	PermissionWrite:    3,
+	PermissionMaintain: 4,
+	PermissionAdmin:    5,
+}
+
+// RegoInputAccess is a principal (user or team) that can access the repository. Permission is effective permission and Grants describe why the principal has access.
+type RegoInputAccess struct {
+	Type       string                  `json:"type"`
+	Login      string                  `json:"login,omitempty"`
+	ID         int64                   `json:"id,omitempty"`
+	Slug       string                  `json:"slug,omitempty"`
+	Permission string                  `json:"permission"`
+	Grants     []*RegoInputAccessGrant `json:"grants"`
+}
+
+// RegoInputAccessGrant is a grant path of repository access. Team is slug of the team that is granted access to the repository. Path is slugs of teams from the team of the principal to Team for grant via parent team. MaybeInherited is true for grant via team if the user is also a member of its child team, because direct membership of the team can not be distinguished from inherited one.
+type RegoInputAccessGrant struct {
+	Via            string   `json:"via"`
+	Team           string   `json:"team,omitempty"`
+	Path           []string `json:"path,omitempty"`
+	MaybeInherited bool     `json:"maybe_inherited,omitempty"`
+	Permission     string   `json:"permission"`
+}
+
+// NormalizePermission converts permission names of REST API (pull, push, etc.) to normalized one.
+func NormalizePermission(perm string) string {
+	switch strings.ToLower(perm) {
+	case "pull", "read":
+		return PermissionRead
+	case "triage":
+		return PermissionTriage
+	case "push", "write":
+		return PermissionWrite
+	case "maintain":
+		return PermissionMaintain
+	case "admin":
+		return PermissionAdmin
+	default:
+		return PermissionNone
+	}
+}
+
+// PermissionFromMap returns the highest permission in permissions field of users and teams.
+func PermissionFromMap(perms map[string]bool) string {
+	for _, p := range []string{"admin", "maintain", "push", "triage", "pull"} {
+		if perms[p] {
+			return NormalizePermission(p)
+		}
+	}
+	return PermissionNone
+}
+
+// PermissionToMap converts normalized permission to permissions field of users and teams.
+func PermissionToMap(perm string) map[string]bool {
+	level := permissionLevel[perm]
+	return map[string]bool{
+		"pull":     level >= permissionLevel[PermissionRead],
+		"triage":   level >= permissionLevel[PermissionTriage],
+		"push":     level >= permissionLevel[PermissionWrite],
+		"maintain": level >= permissionLevel[PermissionMaintain],
+		"admin":    level >= permissionLevel[PermissionAdmin],
+	}
+}
+
+func higherPermission(a, b string) string {
+	if permissionLevel[a] >= permissionLevel[b] {
+		return a
+	}
+	return b
+}
+
+func teamPermission(team *github.Team) string {
+	if team.Permissions != nil {
+		return PermissionFromMap(team.Permissions)
+	}
+	return NormalizePermission(team.GetPermission())
+}
+
+// AccessSource is a set of data to calculate effective access of a repository. Org may be nil if organization data is not available.
+type AccessSource struct {
+	Collaborators       []*github.User
+	DirectCollaborators []*github.User
+	Teams               []*github.Team
+	Org                 *RegoOrgInput
+}
+
+// BuildAccess calculates principals that can access the repository with effective permission and grant paths.
+func BuildAccess(src *AccessSource) []*RegoInputAccess {
+	var result []*RegoInputAccess
+
+	// Teams: directly granted teams and their child teams
+	teamGrants := make(map[string][]*RegoInputAccessGrant)
+	repoTeams := make(map[string]string)
+	for _, team := range src.Teams {
+		repoTeams[team.GetSlug()] = teamPermission(team)
+		teamGrants[team.GetSlug()] = append(teamGrants[team.GetSlug()], &RegoInputAccessGrant{
+			Via:        AccessViaTeam,
+			Team:       team.GetSlug(),
+			Permission: teamPermission(team),
+		})
+	}
+	orgTeams := make(map[string]*RegoInputTeam)
+	if src.Org != nil {
+		for _, team := range src.Org.Teams {
+			orgTeams[team.GetSlug()] = team
+		}
+		for _, team := range src.Org.Teams {
+			path := []string{team.GetSlug()}
+			for parent := team.GetParent(); parent != nil; parent = parentTeam(orgTeams, parent.GetSlug()) {
+				path = append(path, parent.GetSlug())
+				if perm, ok := repoTeams[parent.GetSlug()]; ok {
+					teamGrants[team.GetSlug()] = append(teamGrants[team.GetSlug()], &RegoInputAccessGrant{
+						Via:        AccessViaParentTeam,
+						Team:       parent.GetSlug(),
+						Path:       append([]string{}, path...),
+						Permission: perm,
+					})
+				}
+			}
+		}
+	}
+	for _, slug := range sortedKeys(teamGrants) {
+		result = append(result, newAccess(&RegoInputAccess{
+			Type: AccessTypeTeam,
+			Slug: slug,
+		}, teamGrants[slug], ""))
+	}
+
+	// Users
+	teamMemberGrants := make(map[int64][]*RegoInputAccessGrant)
+	for _, slug := range sortedKeys(teamGrants) {
+		team, ok := orgTeams[slug]
+		if !ok {
+			continue
+		}
+		for _, member := range team.Members {
+			teamMemberGrants[member.GetID()] = appendGrants(teamMemberGrants[member.GetID()], teamGrants[slug]...)
+		}
+	}
+	for id, grants := range teamMemberGrants {
+		teamMemberGrants[id] = markInheritedTeamGrants(grants)
+	}
+
+	direct := make(map[int64]*github.User)
+	for _, user := range src.DirectCollaborators {
+		direct[user.GetID()] = user
+	}
+	members := make(map[int64]*RegoInputOrgMember)
+	basePerm := PermissionNone
+	if src.Org != nil {
+		for _, member := range src.Org.Members {
+			members[member.GetID()] = member
+		}
+		basePerm = NormalizePermission(src.Org.Org.GetDefaultRepoPermission())
+	}
+
+	for _, user := range src.Collaborators {
+		// fallback to the highest permission of grants if permissions field is not available
+		perm := PermissionFromMap(user.GetPermissions())
+		if perm == PermissionNone {
+			perm = ""
+		}
+
+		var grants []*RegoInputAccessGrant
+		if d, ok := direct[user.GetID()]; ok {
+			grants = append(grants, &RegoInputAccessGrant{
+				Via:        AccessViaDirect,
+				Permission: PermissionFromMap(d.GetPermissions()),
+			})
+		}
+		grants = append(grants, teamMemberGrants[user.GetID()]...)
+		if member, ok := members[user.GetID()]; ok {
+			if member.Role == "admin" {
+				grants = append(grants, &RegoInputAccessGrant{
+					Via:        AccessViaOrgOwner,
+					Permission: PermissionAdmin,
+				})
+			}
+			if basePerm != PermissionNone {
+				grants = append(grants, &RegoInputAccessGrant{
+					Via:        AccessViaOrgBase,
+					Permission: basePerm,
+				})
+			}
+		}
+
+		result = append(result, newAccess(&RegoInputAccess{
+			Type:  AccessTypeUser,
+			Login: user.GetLogin(),
+			ID:    user.GetID(),
+		}, grants, perm))
+	}
+
+	return result
+}
+
+// newAccess sets grants and permission to access. The effective permission is the highest one of grants if perm is empty. A grant via unknown path is added if grants do not explain perm.
+func newAccess(access *RegoInputAccess, grants []*RegoInputAccessGrant, perm string) *RegoInputAccess {
+	highest := PermissionNone
+	for _, grant := range grants {
+		highest = higherPermission(highest, grant.Permission)
+	}
+	if perm == "" {
+		perm = highest
+	}
+	if permissionLevel[highest] < permissionLevel[perm] {
+		grants = append(grants, &RegoInputAccessGrant{
+			Via:        AccessViaUnknown,
+			Permission: perm,
+		})
+	}
+
+	access.Permission = perm
+	access.Grants = grants
+	return access
+}
+
+func parentTeam(teams map[string]*RegoInputTeam, slug string) *github.Team {
+	if team, ok := teams[slug]; ok {
+		return team.GetParent()
+	}
+	return nil
+}
+
+// appendGrants appends grants that have not been added yet.
+func appendGrants(grants []*RegoInputAccessGrant, newGrants ...*RegoInputAccessGrant) []*RegoInputAccessGrant {
+	for _, g := range newGrants {
+		dup := false
+		for _, exists := range grants {
+			if exists.Via == g.Via && exists.Team == g.Team && strings.Join(exists.Path, "/") == strings.Join(g.Path, "/") {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			grants = append(grants, g)
+		}
+	}
+	return grants
+}
+
+// markInheritedTeamGrants marks grant via team T as MaybeInherited if the same user has grant via parent team T. Member list of a team includes members of its child teams, so a member of child team appears as a member of T even if the user is not a direct member of T.
+func markInheritedTeamGrants(grants []*RegoInputAccessGrant) []*RegoInputAccessGrant {
+	inherited := make(map[string]bool)
+	for _, g := range grants {
+		if g.Via == AccessViaParentTeam {
+			inherited[g.Team] = true
+		}
+	}
+
+	resp := make([]*RegoInputAccessGrant, len(grants))
+	for i, g := range grants {
+		resp[i] = g
+		if g.Via == AccessViaTeam && inherited[g.Team] {
+			// grants are shared by members of the team
+			marked := *g
+			marked.MaybeInherited = true
+			resp[i] = &marked
+		}
+	}
+	return resp
+}
+
+func sortedKeys(m map[string][]*RegoInputAccessGrant) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,186 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAccess(t *testing.T) {
+	org := &model.RegoOrgInput{
+		Org: &github.Organization{
+			Login:                 github.String("my-org"),
+			DefaultRepoPermission: github.String("read"),
+		},
+		Members: []*model.RegoInputOrgMember{
+			{User: github.User{ID: github.Int64(1), Login: github.String("owner")}, Role: "admin"},
+			{User: github.User{ID: github.Int64(2), Login: github.String("alice")}, Role: "member"},
+		},
+		Teams: []*model.RegoInputTeam{
+			{
+				Team:    github.Team{Slug: github.String("eng")},
+				Members: []*github.User{{ID: github.Int64(4), Login: github.String("carol")}},
+			},
+			{
+				Team: github.Team{Slug: github.String("backend"), Parent: &github.Team{Slug: github.String("eng")}},
+			},
+			{
+				Team:    github.Team{Slug: github.String("db"), Parent: &github.Team{Slug: github.String("backend")}},
+				Members: []*github.User{{ID: github.Int64(4), Login: github.String("carol")}},
+			},
+			{
+				Team:    github.Team{Slug: github.String("sales")},
+				Members: []*github.User{{ID: github.Int64(3), Login: github.String("bob")}},
+			},
+		},
+	}
+
+	access := model.BuildAccess(&model.AccessSource{
+		Collaborators: []*github.User{
+			{ID: github.Int64(1), Login: github.String("owner"), Permissions: model.PermissionToMap(model.PermissionAdmin)},
+			{ID: github.Int64(2), Login: github.String("alice"), Permissions: model.PermissionToMap(model.PermissionMaintain)},
+			{ID: github.Int64(3), Login: github.String("bob"), Permissions: model.PermissionToMap(model.PermissionWrite)},
+			{ID: github.Int64(4), Login: github.String("carol"), Permissions: model.PermissionToMap(model.PermissionWrite)},
+		},
+		DirectCollaborators: []*github.User{
+			{ID: github.Int64(3), Login: github.String("bob"), Permissions: model.PermissionToMap(model.PermissionWrite)},
+		},
+		Teams: []*github.Team{
+			{Slug: github.String("eng"), Permission: github.String("push")},
+		},
+		Org: org,
+	})
+
+	principals := map[string]*model.RegoInputAccess{}
+	for _, a := range access {
+		principals[a.Type+":"+a.Login+a.Slug] = a
+	}
+	require.Len(t, principals, 7)
+
+	t.Run("team granted directly", func(t *testing.T) {
+		eng := principals["team:eng"]
+		assert.Equal(t, model.PermissionWrite, eng.Permission)
+		require.Len(t, eng.Grants, 1)
+		assert.Equal(t, model.AccessViaTeam, eng.Grants[0].Via)
+	})
+
+	t.Run("child teams inherit parent team grant", func(t *testing.T) {
+		for _, slug := range []string{"backend", "db"} {
+			team := principals["team:"+slug]
+			assert.Equal(t, model.PermissionWrite, team.Permission)
+			require.Len(t, team.Grants, 1)
+			assert.Equal(t, model.AccessViaParentTeam, team.Grants[0].Via)
+			assert.Equal(t, "eng", team.Grants[0].Team)
+		}
+		assert.Equal(t, []string{"db", "backend", "eng"}, principals["team:db"].Grants[0].Path)
+		assert.NotContains(t, principals, "team:sales")
+	})
+
+	t.Run("org owner", func(t *testing.T) {
+		owner := principals["user:owner"]
+		assert.Equal(t, model.PermissionAdmin, owner.Permission)
+		var vias []string
+		for _, g := range owner.Grants {
+			vias = append(vias, g.Via)
+		}
+		assert.Equal(t, []string{model.AccessViaOrgOwner, model.AccessViaOrgBase}, vias)
+	})
+
+	t.Run("unexplained permission is marked as unknown", func(t *testing.T) {
+		alice := principals["user:alice"]
+		assert.Equal(t, model.PermissionMaintain, alice.Permission)
+		require.Len(t, alice.Grants, 2)
+		assert.Equal(t, model.AccessViaOrgBase, alice.Grants[0].Via)
+		assert.Equal(t, model.AccessViaUnknown, alice.Grants[1].Via)
+	})
+
+	t.Run("direct collaborator", func(t *testing.T) {
+		bob := principals["user:bob"]
+		assert.Equal(t, model.PermissionWrite, bob.Permission)
+		require.Len(t, bob.Grants, 1)
+		assert.Equal(t, model.AccessViaDirect, bob.Grants[0].Via)
+	})
+
+	t.Run("team member", func(t *testing.T) {
+		// carol is listed in eng as a member of its descendant team db, and may or may not be a direct member of eng
+		carol := principals["user:carol"]
+		assert.Equal(t, model.PermissionWrite, carol.Permission)
+		require.Len(t, carol.Grants, 2)
+		assert.Equal(t, model.AccessViaParentTeam, carol.Grants[0].Via)
+		assert.Equal(t, "eng", carol.Grants[0].Team)
+		assert.Equal(t, []string{"db", "backend", "eng"}, carol.Grants[0].Path)
+		assert.Equal(t, model.AccessViaTeam, carol.Grants[1].Via)
+		assert.Equal(t, "eng", carol.Grants[1].Team)
+		assert.True(t, carol.Grants[1].MaybeInherited)
+	})
+}
+
+func TestBuildAccessNestedTeams(t *testing.T) {
+	user := func(id int64, login string) *github.User {
+		return &github.User{ID: github.Int64(id), Login: github.String(login)}
+	}
+	alice, bob, carol, dave := user(1, "alice"), user(2, "bob"), user(3, "carol"), user(4, "dave")
+
+	// member list of a team includes members of its child teams. dave is a direct member of both eng and backend, but it is indistinguishable from bob
+	org := &model.RegoOrgInput{
+		Org: &github.Organization{Login: github.String("my-org")},
+		Teams: []*model.RegoInputTeam{
+			{
+				Team:    github.Team{Slug: github.String("eng")},
+				Members: []*github.User{alice, bob, carol, dave},
+			},
+			{
+				Team:    github.Team{Slug: github.String("backend"), Parent: &github.Team{Slug: github.String("eng")}},
+				Members: []*github.User{bob, carol, dave},
+			},
+			{
+				Team:    github.Team{Slug: github.String("frontend"), Parent: &github.Team{Slug: github.String("eng")}},
+				Members: []*github.User{carol},
+			},
+		},
+	}
+
+	access := model.BuildAccess(&model.AccessSource{
+		Collaborators: []*github.User{alice, bob, carol, dave},
+		Teams: []*github.Team{
+			{Slug: github.String("eng"), Permission: github.String("push")},
+			{Slug: github.String("backend"), Permission: github.String("admin")},
+		},
+		Org: org,
+	})
+
+	grants := map[string][]model.RegoInputAccessGrant{}
+	for _, a := range access {
+		for _, g := range a.Grants {
+			grants[a.Login+a.Slug] = append(grants[a.Login+a.Slug], *g)
+		}
+	}
+
+	// direct membership of eng is kept as possibly inherited one
+	for _, login := range []string{"bob", "dave"} {
+		assert.Equal(t, []model.RegoInputAccessGrant{
+			{Via: model.AccessViaTeam, Team: "backend", Permission: model.PermissionAdmin},
+			{Via: model.AccessViaParentTeam, Team: "eng", Path: []string{"backend", "eng"}, Permission: model.PermissionWrite},
+			{Via: model.AccessViaTeam, Team: "eng", MaybeInherited: true, Permission: model.PermissionWrite},
+		}, grants[login], login)
+	}
+
+	assert.Equal(t, []model.RegoInputAccessGrant{
+		{Via: model.AccessViaTeam, Team: "backend", Permission: model.PermissionAdmin},
+		{Via: model.AccessViaParentTeam, Team: "eng", Path: []string{"backend", "eng"}, Permission: model.PermissionWrite},
+		{Via: model.AccessViaTeam, Team: "eng", MaybeInherited: true, Permission: model.PermissionWrite},
+		{Via: model.AccessViaParentTeam, Team: "eng", Path: []string{"frontend", "eng"}, Permission: model.PermissionWrite},
+	}, grants["carol"])
+
+	// grant of other members is not marked
+	assert.Equal(t, []model.RegoInputAccessGrant{
+		{Via: model.AccessViaTeam, Team: "eng", Permission: model.PermissionWrite},
+	}, grants["alice"])
+
+	assert.Equal(t, []model.RegoInputAccessGrant{
+		{Via: model.AccessViaParentTeam, Team: "eng", Path: []string{"frontend", "eng"}, Permission: model.PermissionWrite},
+	}, grants["frontend"])
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// RegoInputActivity is activity signals of a repository. Days fields are calculated from scan time and nil if there is no corresponding activity.
+type RegoInputActivity struct {
+	LastCommit            *ActivityCommit        `json:"last_commit"`
+	LastRelease           *ActivityRelease       `json:"last_release"`
+	OpenPullRequests      []*ActivityPullRequest `json:"open_pull_requests"`
+	OpenPullRequestCount  int                    `json:"open_pull_request_count"`
+	OldestPullRequestDays *int                   `json:"oldest_pull_request_days"`
+	DaysSinceLastCommit   *int                   `json:"days_since_last_commit"`
+	DaysSinceLastRelease  *int                   `json:"days_since_last_release"`
+}
+
+// ActivityCommit is a commit in default branch. Author is login of GitHub user if the commit is linked to a user, otherwise author name of the commit.
+type ActivityCommit struct {
+	SHA    string    `json:"sha"`
+	Author string    `json:"author"`
+	Date   time.Time `json:"date"`
+}
+
+type ActivityPullRequest struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	User      string    `json:"user"`
+	Draft     bool      `json:"draft"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ActivityRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func daysSince(t, now time.Time) *int {
+	days := int(now.Sub(t).Hours() / 24)
+	return &days
+}
+
+// NewActivityCommit converts a commit of REST API. Committer date is used as commit date because it is the time when the commit was applied to the branch.
+func NewActivityCommit(commit *github.RepositoryCommit) *ActivityCommit {
+	author := commit.GetAuthor().GetLogin()
+	if author == "" {
+		author = commit.GetCommit().GetAuthor().GetName()
+	}
+	date := commit.GetCommit().GetCommitter().GetDate()
+	if date.IsZero() {
+		date = commit.GetCommit().GetAuthor().GetDate()
+	}
+
+	return &ActivityCommit{
+		SHA:    commit.GetSHA(),
+		Author: author,
+		Date:   date,
+	}
+}
+
+// NewRegoInputActivity builds activity signals. commits must be ordered by newest first, and release may be nil if the repository has no release.
+func NewRegoInputActivity(commits []*github.RepositoryCommit, pulls []*github.PullRequest, release *github.RepositoryRelease, now time.Time) *RegoInputActivity {
+	activity := &RegoInputActivity{
+		OpenPullRequests:     []*ActivityPullRequest{},
+		OpenPullRequestCount: len(pulls),
+	}
+
+	if len(commits) > 0 {
+		activity.LastCommit = NewActivityCommit(commits[0])
+	}
+
+	for _, pr := range pulls {
+		activity.OpenPullRequests = append(activity.OpenPullRequests, &ActivityPullRequest{
+			Number:    pr.GetNumber(),
+			Title:     pr.GetTitle(),
+			User:      pr.GetUser().GetLogin(),
+			Draft:     pr.GetDraft(),
+			HTMLURL:   pr.GetHTMLURL(),
+			CreatedAt: pr.GetCreatedAt(),
+			UpdatedAt: pr.GetUpdatedAt(),
+		})
+	}
+
+	if release != nil {
+		activity.LastRelease = &ActivityRelease{
+			TagName:     release.GetTagName(),
+			Name:        release.GetName(),
+			HTMLURL:     release.GetHTMLURL(),
+			PublishedAt: release.GetPublishedAt().Time,
+		}
+	}
+
+	activity.Refresh(now)
+	return activity
+}
+
+// Refresh recalculates days fields from now. It is used to reuse activity collected in previous audit.
+func (x *RegoInputActivity) Refresh(now time.Time) {
+	x.DaysSinceLastCommit, x.OldestPullRequestDays, x.DaysSinceLastRelease = nil, nil, nil
+
+	if x.LastCommit != nil {
+		x.DaysSinceLastCommit = daysSince(x.LastCommit.Date, now)
+	}
+
+	var oldest *time.Time
+	for _, pr := range x.OpenPullRequests {
+		if oldest == nil || pr.CreatedAt.Before(*oldest) {
+			oldest = &pr.CreatedAt
+		}
+	}
+	if oldest != nil {
+		x.OldestPullRequestDays = daysSince(*oldest, now)
+	}
+
+	if x.LastRelease != nil {
+		x.DaysSinceLastRelease = daysSince(x.LastRelease.PublishedAt, now)
+	}
+}
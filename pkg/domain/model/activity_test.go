@@ -0,0 +1,60 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegoInputActivity(t *testing.T) {
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("active repository", func(t *testing.T) {
+		commits := []*github.RepositoryCommit{
+			{
+				SHA: github.String("abc"),
+				Commit: &github.Commit{
+					Author:    &github.CommitAuthor{Name: github.String("Alice"), Date: timePtr(now.AddDate(0, 0, -12))},
+					Committer: &github.CommitAuthor{Date: timePtr(now.AddDate(0, 0, -10))},
+				},
+			},
+			{SHA: github.String("def")},
+		}
+		pulls := []*github.PullRequest{
+			{Number: github.Int(2), CreatedAt: timePtr(now.AddDate(0, 0, -3))},
+			{Number: github.Int(1), CreatedAt: timePtr(now.AddDate(0, 0, -30)), User: &github.User{Login: github.String("bob")}},
+		}
+		release := &github.RepositoryRelease{
+			TagName:     github.String("v1.0.0"),
+			PublishedAt: &github.Timestamp{Time: now.AddDate(0, 0, -100)},
+		}
+
+		activity := model.NewRegoInputActivity(commits, pulls, release, now)
+		require.NotNil(t, activity.LastCommit)
+		assert.Equal(t, "abc", activity.LastCommit.SHA)
+		assert.Equal(t, "Alice", activity.LastCommit.Author)
+		assert.Equal(t, 10, *activity.DaysSinceLastCommit)
+		assert.Equal(t, 2, activity.OpenPullRequestCount)
+		assert.Equal(t, 30, *activity.OldestPullRequestDays)
+		assert.Equal(t, "bob", activity.OpenPullRequests[1].User)
+		assert.Equal(t, "v1.0.0", activity.LastRelease.TagName)
+		assert.Equal(t, 100, *activity.DaysSinceLastRelease)
+	})
+
+	t.Run("empty repository", func(t *testing.T) {
+		activity := model.NewRegoInputActivity(nil, nil, nil, now)
+		assert.Nil(t, activity.LastCommit)
+		assert.Nil(t, activity.DaysSinceLastCommit)
+		assert.Nil(t, activity.OldestPullRequestDays)
+		assert.Nil(t, activity.LastRelease)
+		assert.Equal(t, 0, activity.OpenPullRequestCount)
+	})
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// CodeOwnersPaths is a list of CODEOWNERS file location. GitHub uses the first one found.
+var CodeOwnersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+const (
+	CodeOwnerUser  = "user"
+	CodeOwnerTeam  = "team"
+	CodeOwnerEmail = "email"
+)
+
+const (
+	CodeOwnersSyntaxError        = "syntax_error"
+	CodeOwnersUnknownUser        = "unknown_user"
+	CodeOwnersUnknownTeam        = "unknown_team"
+	CodeOwnersTeamWithoutAccess  = "team_without_access"
+	CodeOwnersNoWriteAccess      = "no_write_access"
+	CodeOwnersOtherOrgTeam       = "other_org_team"
+	CodeOwnersUnsupportedPattern = "unsupported_pattern"
+)
+
+type CodeOwners struct {
+	Path    string             `json:"path"`
+	Content string             `json:"content"`
+	Rules   []*CodeOwnersRule  `json:"rules"`
+	Errors  []*CodeOwnersError `json:"errors"`
+}
+
+type CodeOwnersRule struct {
+	Line    int                `json:"line"`
+	Pattern string             `json:"pattern"`
+	Owners  []*CodeOwnersOwner `json:"owners"`
+}
+
+type CodeOwnersOwner struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type CodeOwnersError struct {
+	Line    int    `json:"line"`
+	Kind    string `json:"kind"`
+	Owner   string `json:"owner,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	codeOwnerUserPattern  = regexp.MustCompile(`^@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+	codeOwnerTeamPattern  = regexp.MustCompile(`^@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?/[a-zA-Z0-9_.-]+$`)
+	codeOwnerEmailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// ParseCodeOwners parses CODEOWNERS file content. A line having a syntax error is reported in Errors and is not included in Rules.
+func ParseCodeOwners(path, content string) *CodeOwners {
+	codeOwners := &CodeOwners{
+		Path:    path,
+		Content: content,
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line = stripCodeOwnersComment(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern := fields[0]
+		if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+			codeOwners.Errors = append(codeOwners.Errors, &CodeOwnersError{
+				Line:    lineNo,
+				Kind:    CodeOwnersUnsupportedPattern,
+				Message: fmt.Sprintf("pattern %q uses negation or character range that is not supported", pattern),
+			})
+			continue
+		}
+
+		rule := &CodeOwnersRule{
+			Line:    lineNo,
+			Pattern: pattern,
+		}
+		valid := true
+		for _, name := range fields[1:] {
+			owner := &CodeOwnersOwner{Name: name}
+			switch {
+			case codeOwnerUserPattern.MatchString(name):
+				owner.Type = CodeOwnerUser
+			case codeOwnerTeamPattern.MatchString(name):
+				owner.Type = CodeOwnerTeam
+			case codeOwnerEmailPattern.MatchString(name):
+				owner.Type = CodeOwnerEmail
+			default:
+				codeOwners.Errors = append(codeOwners.Errors, &CodeOwnersError{
+					Line:    lineNo,
+					Kind:    CodeOwnersSyntaxError,
+					Owner:   name,
+					Message: fmt.Sprintf("%q is not a user, team or email", name),
+				})
+				valid = false
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+
+		if valid {
+			codeOwners.Rules = append(codeOwners.Rules, rule)
+		}
+	}
+
+	return codeOwners
+}
+
+// stripCodeOwnersComment removes a comment that begins with `#` at the line head or after white space.
+func stripCodeOwnersComment(line string) string {
+	for i, c := range line {
+		if c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// Validate checks if owners in rules are users and teams that have write access to the repository. Email owners are not validated because they can not be resolved to users. teams are teams granted access to the repository and orgTeams are all teams of the organization to resolve access inherited from parent teams. orgTeams may be nil if organization data is not available, and then existence of teams is not checked.
+func (x *CodeOwners) Validate(org string, collaborators []*github.User, teams []*github.Team, orgTeams []*RegoInputTeam) {
+	users := make(map[string]*github.User)
+	for _, user := range collaborators {
+		users[strings.ToLower(user.GetLogin())] = user
+	}
+	teamMap := make(map[string]*github.Team)
+	for _, team := range teams {
+		teamMap[strings.ToLower(team.GetSlug())] = team
+	}
+	var orgTeamMap map[string]*RegoInputTeam
+	if orgTeams != nil {
+		orgTeamMap = make(map[string]*RegoInputTeam)
+		for _, team := range orgTeams {
+			orgTeamMap[strings.ToLower(team.GetSlug())] = team
+		}
+	}
+
+	for _, rule := range x.Rules {
+		for _, owner := range rule.Owners {
+			if err := validateCodeOwner(owner, org, users, teamMap, orgTeamMap); err != nil {
+				err.Line = rule.Line
+				x.Errors = append(x.Errors, err)
+			}
+		}
+	}
+}
+
+func validateCodeOwner(owner *CodeOwnersOwner, org string, users map[string]*github.User, teams map[string]*github.Team, orgTeams map[string]*RegoInputTeam) *CodeOwnersError {
+	switch owner.Type {
+	case CodeOwnerUser:
+		user, ok := users[strings.ToLower(strings.TrimPrefix(owner.Name, "@"))]
+		if !ok {
+			return &CodeOwnersError{
+				Kind:    CodeOwnersUnknownUser,
+				Owner:   owner.Name,
+				Message: fmt.Sprintf("%s is not a collaborator of the repository", owner.Name),
+			}
+		}
+		if !user.GetPermissions()["push"] {
+			return &CodeOwnersError{
+				Kind:    CodeOwnersNoWriteAccess,
+				Owner:   owner.Name,
+				Message: fmt.Sprintf("%s does not have write access to the repository", owner.Name),
+			}
+		}
+
+	case CodeOwnerTeam:
+		parts := strings.SplitN(strings.TrimPrefix(owner.Name, "@"), "/", 2)
+		if !strings.EqualFold(parts[0], org) {
+			return &CodeOwnersError{
+				Kind:    CodeOwnersOtherOrgTeam,
+				Owner:   owner.Name,
+				Message: fmt.Sprintf("%s is not a team of %s", owner.Name, org),
+			}
+		}
+		slug := strings.ToLower(parts[1])
+		if _, ok := orgTeams[slug]; orgTeams != nil && !ok {
+			return &CodeOwnersError{
+				Kind:    CodeOwnersUnknownTeam,
+				Owner:   owner.Name,
+				Message: fmt.Sprintf("%s does not exist in %s", owner.Name, org),
+			}
+		}
+		perm, granted := teamRepoPermission(slug, teams, orgTeams)
+		if !granted {
+			return &CodeOwnersError{
+				Kind:    CodeOwnersTeamWithoutAccess,
+				Owner:   owner.Name,
+				Message: fmt.Sprintf("%s does not have access to the repository", owner.Name),
+			}
+		}
+		if permissionLevel[perm] < permissionLevel[PermissionWrite] {
+			return &CodeOwnersError{
+				Kind:    CodeOwnersNoWriteAccess,
+				Owner:   owner.Name,
+				Message: fmt.Sprintf("%s does not have write access to the repository", owner.Name),
+			}
+		}
+	}
+
+	return nil
+}
+
+// teamRepoPermission returns the highest permission of the team granted directly or via its ancestor teams. granted is false if neither the team nor its ancestors have access.
+func teamRepoPermission(slug string, teams map[string]*github.Team, orgTeams map[string]*RegoInputTeam) (string, bool) {
+	perm, granted := PermissionNone, false
+	visited := make(map[string]bool)
+	for slug != "" && !visited[slug] {
+		visited[slug] = true
+		if team, ok := teams[slug]; ok {
+			perm = higherPermission(perm, teamPermission(team))
+			granted = true
+		}
+
+		orgTeam, ok := orgTeams[slug]
+		if !ok {
+			break
+		}
+		slug = strings.ToLower(orgTeam.GetParent().GetSlug())
+	}
+	return perm, granted
+}
@@ -0,0 +1,101 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	content := `# global owners
+*       @my-org/core @alice
+
+/docs/  docs@example.com # inline comment
+!/vendor/ @bob
+*.go    @my-org/go invalid_owner
+/empty/
+`
+	co := model.ParseCodeOwners(".github/CODEOWNERS", content)
+	require.Len(t, co.Rules, 3)
+
+	assert.Equal(t, 2, co.Rules[0].Line)
+	assert.Equal(t, "*", co.Rules[0].Pattern)
+	require.Len(t, co.Rules[0].Owners, 2)
+	assert.Equal(t, model.CodeOwnerTeam, co.Rules[0].Owners[0].Type)
+	assert.Equal(t, model.CodeOwnerUser, co.Rules[0].Owners[1].Type)
+
+	assert.Equal(t, "/docs/", co.Rules[1].Pattern)
+	require.Len(t, co.Rules[1].Owners, 1)
+	assert.Equal(t, model.CodeOwnerEmail, co.Rules[1].Owners[0].Type)
+
+	assert.Equal(t, "/empty/", co.Rules[2].Pattern)
+	assert.Len(t, co.Rules[2].Owners, 0)
+
+	require.Len(t, co.Errors, 2)
+	assert.Equal(t, 5, co.Errors[0].Line)
+	assert.Equal(t, model.CodeOwnersUnsupportedPattern, co.Errors[0].Kind)
+	assert.Equal(t, 6, co.Errors[1].Line)
+	assert.Equal(t, model.CodeOwnersSyntaxError, co.Errors[1].Kind)
+	assert.Equal(t, "invalid_owner", co.Errors[1].Owner)
+}
+
+func TestCodeOwnersValidate(t *testing.T) {
+	content := `* @alice @bob @carol @my-org/core @my-org/readers @my-org/ghost @my-org/hidden @my-org/db @my-org/docs @other-org/core
+`
+	collaborators := []*github.User{
+		{Login: github.String("Alice"), Permissions: map[string]bool{"push": true}},
+		{Login: github.String("bob"), Permissions: map[string]bool{"pull": true}},
+	}
+	teams := []*github.Team{
+		{Slug: github.String("core"), Permission: github.String("push")},
+		{Slug: github.String("readers"), Permission: github.String("pull")},
+		{Slug: github.String("backend"), Permission: github.String("push")},
+	}
+	orgTeams := []*model.RegoInputTeam{
+		{Team: github.Team{Slug: github.String("core")}},
+		{Team: github.Team{Slug: github.String("readers")}},
+		{Team: github.Team{Slug: github.String("hidden")}},
+		{Team: github.Team{Slug: github.String("backend")}},
+		{Team: github.Team{Slug: github.String("db"), Parent: &github.Team{Slug: github.String("backend")}}},
+		{Team: github.Team{Slug: github.String("docs"), Parent: &github.Team{Slug: github.String("readers")}}},
+	}
+
+	validate := func(orgTeams []*model.RegoInputTeam) map[string]string {
+		co := model.ParseCodeOwners("CODEOWNERS", content)
+		co.Validate("my-org", collaborators, teams, orgTeams)
+		errs := map[string]string{}
+		for _, err := range co.Errors {
+			errs[err.Owner] = err.Kind
+		}
+		return errs
+	}
+
+	t.Run("with organization teams", func(t *testing.T) {
+		// db inherits write access from backend, and docs inherits read access from readers
+		assert.Equal(t, map[string]string{
+			"@bob":            model.CodeOwnersNoWriteAccess,
+			"@carol":          model.CodeOwnersUnknownUser,
+			"@my-org/readers": model.CodeOwnersNoWriteAccess,
+			"@my-org/ghost":   model.CodeOwnersUnknownTeam,
+			"@my-org/hidden":  model.CodeOwnersTeamWithoutAccess,
+			"@my-org/docs":    model.CodeOwnersNoWriteAccess,
+			"@other-org/core": model.CodeOwnersOtherOrgTeam,
+		}, validate(orgTeams))
+	})
+
+	t.Run("without organization teams", func(t *testing.T) {
+		assert.Equal(t, map[string]string{
+			"@bob":            model.CodeOwnersNoWriteAccess,
+			"@carol":          model.CodeOwnersUnknownUser,
+			"@my-org/readers": model.CodeOwnersNoWriteAccess,
+			"@my-org/ghost":   model.CodeOwnersTeamWithoutAccess,
+			"@my-org/hidden":  model.CodeOwnersTeamWithoutAccess,
+			"@my-org/db":      model.CodeOwnersTeamWithoutAccess,
+			"@my-org/docs":    model.CodeOwnersTeamWithoutAccess,
+			"@other-org/core": model.CodeOwnersOtherOrgTeam,
+		}, validate(nil))
+	})
+}
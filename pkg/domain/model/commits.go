@@ -0,0 +1,98 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// RegoInputCommits is signature verification statistics of recent commits in default branch. VerifiedPercent is 0 if there is no commit.
+type RegoInputCommits struct {
+	Total           int                `json:"total"`
+	Signed          int                `json:"signed"`
+	Verified        int                `json:"verified"`
+	VerifiedPercent float64            `json:"verified_percent"`
+	Reasons         map[string]int     `json:"reasons"`
+	UnsignedAuthors []string           `json:"unsigned_authors"`
+	Commits         []*RegoInputCommit `json:"commits"`
+}
+
+// RegoInputCommit is a commit with signature verification status. Reason is `reason` of verification object, e.g. "valid", "unsigned" and "unknown_key".
+type RegoInputCommit struct {
+	ActivityCommit
+	Verified bool   `json:"verified"`
+	Reason   string `json:"reason"`
+}
+
+// Reason of unsigned commit in verification object
+const CommitReasonUnsigned = "unsigned"
+
+// NewRegoInputCommits calculates signature verification statistics of commits.
+func NewRegoInputCommits(commits []*github.RepositoryCommit) *RegoInputCommits {
+	result := &RegoInputCommits{
+		Total:           len(commits),
+		Reasons:         make(map[string]int),
+		UnsignedAuthors: []string{},
+		Commits:         []*RegoInputCommit{},
+	}
+
+	unsignedAuthors := make(map[string]struct{})
+	for _, commit := range commits {
+		verification := commit.GetCommit().GetVerification()
+		c := &RegoInputCommit{
+			ActivityCommit: *NewActivityCommit(commit),
+			Verified:       verification.GetVerified(),
+			Reason:         verification.GetReason(),
+		}
+		if c.Reason == "" {
+			c.Reason = CommitReasonUnsigned
+		}
+
+		result.Reasons[c.Reason]++
+		if c.Verified {
+			result.Verified++
+		}
+		if c.Reason == CommitReasonUnsigned {
+			unsignedAuthors[c.Author] = struct{}{}
+		} else {
+			result.Signed++
+		}
+		result.Commits = append(result.Commits, c)
+	}
+
+	for author := range unsignedAuthors {
+		result.UnsignedAuthors = append(result.UnsignedAuthors, author)
+	}
+	sort.Strings(result.UnsignedAuthors)
+
+	if result.Total > 0 {
+		result.VerifiedPercent = float64(result.Verified) * 100 / float64(result.Total)
+	}
+
+	return result
+}
+
+// RepositoryCommit restores a commit of REST API with fields used by ghaudit.
+func (x *RegoInputCommit) RepositoryCommit() *github.RepositoryCommit {
+	return newRepositoryCommit(&x.ActivityCommit, &github.SignatureVerification{
+		Verified: github.Bool(x.Verified),
+		Reason:   github.String(x.Reason),
+	})
+}
+
+// RepositoryCommit restores a commit of REST API with fields used by ghaudit.
+func (x *ActivityCommit) RepositoryCommit() *github.RepositoryCommit {
+	return newRepositoryCommit(x, nil)
+}
+
+func newRepositoryCommit(commit *ActivityCommit, verification *github.SignatureVerification) *github.RepositoryCommit {
+	date := commit.Date
+	return &github.RepositoryCommit{
+		SHA: github.String(commit.SHA),
+		Commit: &github.Commit{
+			Author:       &github.CommitAuthor{Name: github.String(commit.Author)},
+			Committer:    &github.CommitAuthor{Date: &date},
+			Verification: verification,
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegoInputCommits(t *testing.T) {
+	newCommit := func(author string, verified bool, reason string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{
+			Author: &github.User{Login: github.String(author)},
+			Commit: &github.Commit{
+				Verification: &github.SignatureVerification{
+					Verified: github.Bool(verified),
+					Reason:   github.String(reason),
+				},
+			},
+		}
+	}
+
+	commits := model.NewRegoInputCommits([]*github.RepositoryCommit{
+		newCommit("alice", true, "valid"),
+		newCommit("bob", false, "unsigned"),
+		newCommit("alice", false, "unknown_key"),
+		newCommit("bob", false, "unsigned"),
+		{Author: &github.User{Login: github.String("carol")}},
+	})
+
+	assert.Equal(t, 5, commits.Total)
+	assert.Equal(t, 2, commits.Signed)
+	assert.Equal(t, 1, commits.Verified)
+	assert.Equal(t, 20.0, commits.VerifiedPercent)
+	assert.Equal(t, map[string]int{"valid": 1, "unsigned": 3, "unknown_key": 1}, commits.Reasons)
+	assert.Equal(t, []string{"bob", "carol"}, commits.UnsignedAuthors)
+	require.Len(t, commits.Commits, 5)
+	assert.Equal(t, model.CommitReasonUnsigned, commits.Commits[4].Reason)
+
+	empty := model.NewRegoInputCommits(nil)
+	assert.Equal(t, 0, empty.Total)
+	assert.Equal(t, 0.0, empty.VerifiedPercent)
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"regexp"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -18,11 +19,15 @@ type Config struct {
 	PrivateKeyFile string
 	PrivateKeyData string `zlog:"secret"`
 
-	Policy  string
-	Package string
+	Policy           string
+	Package          string
+	OrgPackage       string
+	AggregatePackage string
 
-	URL     string
-	Headers []string `zlog:"secret"`
+	URL          string
+	OrgURL       string
+	AggregateURL string
+	Headers      []string `zlog:"secret"`
 
 	LogFormat    string
 	LogLevel     string
@@ -30,10 +35,27 @@ type Config struct {
 	Fail         bool
 	SkipArchived bool
 
-	Thread  int64
-	Limit   int64
-	DumpDir string
-	LoadDir string
+	Files       []string
+	FileContent bool
+	Commits     int64
+
+	PropertyFilters []string
+	CollectAll      bool
+	GraphQL         bool
+
+	Thread         int64
+	Concurrency    int64
+	MaxConcurrency int64
+	Limit          int64
+	CacheDir       string
+	CacheTTL       time.Duration
+	StateDir       string
+	FullRefresh    bool
+	RefreshAge     time.Duration
+	WorkDir        string
+	Resume         bool
+	DumpDir        string
+	LoadDir        string
 }
 
 func (x *Config) Validate() error {
@@ -56,16 +78,49 @@ func (x *Config) Validate() error {
 		validation.Field(&x.LogFormat, validation.In("text", "json"), validation.Required),
 		validation.Field(&x.LogLevel, validation.In("trace", "debug", "info", "warn", "error"), validation.Required),
 		validation.Field(&x.URL, is.URL),
+		validation.Field(&x.OrgURL, is.URL),
+		validation.Field(&x.AggregateURL, is.URL),
 		validation.Field(&x.Thread, validation.Min(1)),
+		validation.Field(&x.Concurrency, validation.Min(1)),
+		validation.Field(&x.MaxConcurrency, validation.Min(1)),
 		validation.Field(&x.Limit, validation.Min(0)),
+		validation.Field(&x.CacheTTL, validation.Min(time.Duration(0))),
+		validation.Field(&x.RefreshAge, validation.Min(time.Duration(0))),
+		validation.Field(&x.Commits, validation.Min(0)),
 		validation.Field(&x.SlackWebhook, is.URL),
 	); err != nil {
 		return types.ErrInvalidConfig.Wrap(err)
 	}
 
+	for _, filter := range x.PropertyFilters {
+		if _, err := ParsePropertyFilter(filter); err != nil {
+			return err
+		}
+	}
+
 	if x.Policy == "" && x.URL == "" {
 		return goerr.Wrap(types.ErrInvalidConfig, "either one of policy dir or opa server URL is required")
 	}
 
+	if x.Resume && x.WorkDir == "" {
+		return goerr.Wrap(types.ErrInvalidConfig, "work dir is required to resume audit")
+	}
+
 	return nil
 }
+
+// Workers returns number of repositories retrieved concurrently. Thread larger than Concurrency is respected for backward compatibility because Thread used to be number of workers.
+func (x *Config) Workers() int64 {
+	if x.Concurrency < x.Thread {
+		return x.Thread
+	}
+	return x.Concurrency
+}
+
+// MaxRequests returns maximum number of concurrent GitHub API requests. It is not less than Thread, the initial number of concurrent requests.
+func (x *Config) MaxRequests() int64 {
+	if x.MaxConcurrency < x.Thread {
+		return x.Thread
+	}
+	return x.MaxConcurrency
+}
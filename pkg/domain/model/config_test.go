@@ -0,0 +1,24 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigConcurrency(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cfg := &model.Config{LoadDir: "./data", LogFormat: "text", LogLevel: "info", Policy: "./policy", Thread: 1, Concurrency: 4, MaxConcurrency: 16}
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, int64(4), cfg.Workers())
+		assert.Equal(t, int64(16), cfg.MaxRequests())
+	})
+
+	t.Run("large thread is respected", func(t *testing.T) {
+		cfg := &model.Config{LoadDir: "./data", LogFormat: "text", LogLevel: "info", Policy: "./policy", Thread: 32, Concurrency: 4, MaxConcurrency: 16}
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, int64(32), cfg.Workers())
+		assert.Equal(t, int64(32), cfg.MaxRequests())
+	})
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RegoInputDependencies is a summary of packages in SBOM of a repository. Ecosystems and Licenses are number of packages by ecosystem and license.
+type RegoInputDependencies struct {
+	Total      int                 `json:"total"`
+	Ecosystems map[string]int      `json:"ecosystems"`
+	Licenses   map[string]int      `json:"licenses"`
+	Packages   []*RegoInputPackage `json:"packages"`
+}
+
+// RegoInputPackage is a dependency package. License is an SPDX license expression (e.g. "MIT OR Apache-2.0") and empty if unknown.
+type RegoInputPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	License   string `json:"license"`
+	PURL      string `json:"purl"`
+}
+
+var sbomNamePrefix = regexp.MustCompile(`^([a-z]+):`)
+
+const (
+	sbomNoAssertion       = "NOASSERTION"
+	sbomRelationDescribes = "DESCRIBES"
+)
+
+// NewRegoInputDependencies summarizes packages of SBOM. Packages described by the document (the repository itself) are excluded. It returns nil if sbom is nil.
+func NewRegoInputDependencies(sbom *SBOM) *RegoInputDependencies {
+	if sbom == nil {
+		return nil
+	}
+
+	roots := make(map[string]bool)
+	for _, rel := range sbom.Relationships {
+		if rel.RelationshipType == sbomRelationDescribes && rel.SPDXElementID == sbom.SPDXID {
+			roots[rel.RelatedSPDXElement] = true
+		}
+	}
+
+	deps := &RegoInputDependencies{
+		Ecosystems: make(map[string]int),
+		Licenses:   make(map[string]int),
+		Packages:   []*RegoInputPackage{},
+	}
+	for _, pkg := range sbom.Packages {
+		if roots[pkg.SPDXID] {
+			continue
+		}
+		p := newRegoInputPackage(pkg)
+		deps.Packages = append(deps.Packages, p)
+
+		deps.Ecosystems[p.Ecosystem]++
+		license := p.License
+		if license == "" {
+			license = "unknown"
+		}
+		deps.Licenses[license]++
+	}
+	deps.Total = len(deps.Packages)
+
+	sort.Slice(deps.Packages, func(i, j int) bool {
+		a, b := deps.Packages[i], deps.Packages[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	return deps
+}
+
+// newRegoInputPackage converts SBOM package. Ecosystem is type of package URL, or prefix of name (e.g. "npm" of "npm:lodash") added by GitHub if package URL is not available. The prefix is removed from name.
+func newRegoInputPackage(pkg *SBOMPackage) *RegoInputPackage {
+	p := &RegoInputPackage{
+		Name:    pkg.Name,
+		Version: pkg.VersionInfo,
+	}
+
+	for _, ref := range pkg.ExternalRefs {
+		if ref.ReferenceType == "purl" {
+			p.PURL = ref.ReferenceLocator
+			break
+		}
+	}
+	if strings.HasPrefix(p.PURL, "pkg:") {
+		if idx := strings.Index(p.PURL, "/"); idx > 0 {
+			p.Ecosystem = p.PURL[len("pkg:"):idx]
+		}
+	}
+
+	if m := sbomNamePrefix.FindStringSubmatch(p.Name); m != nil {
+		if p.Ecosystem == "" {
+			p.Ecosystem = m[1]
+		}
+		p.Name = p.Name[len(m[0]):]
+	}
+
+	for _, license := range []string{pkg.LicenseConcluded, pkg.LicenseDeclared} {
+		if license != "" && license != sbomNoAssertion {
+			p.License = license
+			break
+		}
+	}
+
+	return p
+}
+
+// SBOMPackage restores SBOM package with fields used by ghaudit.
+func (x *RegoInputPackage) SBOMPackage() *SBOMPackage {
+	pkg := &SBOMPackage{
+		Name:             x.Name,
+		VersionInfo:      x.Version,
+		LicenseConcluded: x.License,
+	}
+	if x.Ecosystem != "" && x.PURL == "" {
+		pkg.Name = x.Ecosystem + ":" + x.Name
+	}
+	if x.PURL != "" {
+		pkg.ExternalRefs = []*SBOMExternalRef{
+			{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  x.PURL,
+			},
+		}
+	}
+	return pkg
+}
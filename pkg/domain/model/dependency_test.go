@@ -0,0 +1,48 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegoInputDependencies(t *testing.T) {
+	purl := func(locator string) []*model.SBOMExternalRef {
+		return []*model.SBOMExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: locator}}
+	}
+
+	sbom := &model.SBOM{
+		SPDXID: "SPDXRef-DOCUMENT",
+		Packages: []*model.SBOMPackage{
+			{SPDXID: "SPDXRef-repo", Name: "com.github.my-org/my-repo", LicenseConcluded: "NOASSERTION"},
+			{SPDXID: "SPDXRef-1", Name: "npm:lodash", VersionInfo: "4.17.21", LicenseConcluded: "MIT", ExternalRefs: purl("pkg:npm/lodash@4.17.21")},
+			{SPDXID: "SPDXRef-2", Name: "go:github.com/foo/bar", VersionInfo: "v1.0.0", LicenseDeclared: "GPL-3.0-only", ExternalRefs: purl("pkg:golang/github.com/foo/bar@v1.0.0")},
+			{SPDXID: "SPDXRef-3", Name: "pip:requests", VersionInfo: "2.0.0", LicenseConcluded: "NOASSERTION"},
+		},
+		Relationships: []*model.SBOMRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-repo"},
+		},
+	}
+
+	deps := model.NewRegoInputDependencies(sbom)
+	require.NotNil(t, deps)
+	assert.Equal(t, 3, deps.Total)
+	assert.Equal(t, map[string]int{"npm": 1, "golang": 1, "pip": 1}, deps.Ecosystems)
+	assert.Equal(t, map[string]int{"MIT": 1, "GPL-3.0-only": 1, "unknown": 1}, deps.Licenses)
+
+	require.Len(t, deps.Packages, 3)
+	assert.Equal(t, &model.RegoInputPackage{
+		Ecosystem: "golang",
+		Name:      "github.com/foo/bar",
+		Version:   "v1.0.0",
+		License:   "GPL-3.0-only",
+		PURL:      "pkg:golang/github.com/foo/bar@v1.0.0",
+	}, deps.Packages[0])
+	assert.Equal(t, "lodash", deps.Packages[1].Name)
+	assert.Equal(t, "pip", deps.Packages[2].Ecosystem)
+	assert.Equal(t, "requests", deps.Packages[2].Name)
+
+	assert.Nil(t, model.NewRegoInputDependencies(nil))
+}
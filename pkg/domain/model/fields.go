@@ -0,0 +1,209 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// InputFields is a set of top-level field names of RegoInput (e.g. "branches") that policies refer. nil InputFields means all fields are required.
+type InputFields map[string]struct{}
+
+// Has returns true if the field is required by policies.
+func (x InputFields) Has(name string) bool {
+	if x == nil {
+		return true
+	}
+	_, ok := x[name]
+	return ok
+}
+
+// HasAny returns true if one of the fields is required by policies.
+func (x InputFields) HasAny(names ...string) bool {
+	for _, name := range names {
+		if x.Has(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns true if all fields are required.
+func (x InputFields) All() bool {
+	return x == nil
+}
+
+// Any returns true if at least one field is required.
+func (x InputFields) Any() bool {
+	return x.All() || len(x) > 0
+}
+
+// Names returns sorted field names. It returns nil if all fields are required.
+func (x InputFields) Names() []string {
+	if x == nil {
+		return nil
+	}
+
+	names := []string{}
+	for name := range x {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewInputFields analyzes Rego modules (file name and source) statically and returns fields of RegoInput referred as `input.<field>` in repository policy and `input.repos[_].<field>` in aggregate policy. A variable bound to a repository of aggregate input by `r := input.repos[_]` or `some r in input.repos` is also tracked as `r.<field>`. Modules of organization policy are ignored and modules of other packages (e.g. libraries) are analyzed as both.
+//
+// It returns nil (all fields) if a policy refers input in a way that can not be analyzed, such as `input[x]`, `x := input` and `f(r)` with a tracked variable.
+func NewInputFields(modules map[string]string, aggregatePackage, orgPackage string) (InputFields, error) {
+	fields := InputFields{}
+
+	for name, src := range modules {
+		module, err := ast.ParseModule(name, src)
+		if err != nil {
+			return nil, types.ErrInvalidConfig.Wrap(err).With("file", name)
+		}
+
+		pkg := module.Package.Path.String()
+		if pkg == "data."+orgPackage {
+			continue
+		}
+
+		if !analyzeModule(module, pkg == "data."+aggregatePackage, fields) {
+			return nil, nil
+		}
+	}
+
+	return fields, nil
+}
+
+// HasPackage returns true if one of Rego modules (file name and source) belongs to the package.
+func HasPackage(modules map[string]string, pkg string) (bool, error) {
+	for name, src := range modules {
+		module, err := ast.ParseModule(name, src)
+		if err != nil {
+			return false, types.ErrInvalidConfig.Wrap(err).With("file", name)
+		}
+		if module.Package.Path.String() == "data."+pkg {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var inputVar = ast.InputRootDocument.Value.(ast.Var)
+
+// analyzeModule adds fields referred in module. It returns false if all fields are required.
+func analyzeModule(module *ast.Module, aggregate bool, fields InputFields) bool {
+	// variables bound to a repository of aggregate input and number of the bindings
+	aliases := make(map[ast.Var]int)
+	// references of input.repos that are already analyzed as binding
+	bound := make(map[*ast.Term]struct{})
+
+	bind := func(v, ref *ast.Term, length int) {
+		if name, ok := v.Value.(ast.Var); ok && isReposRef(ref, length) {
+			aliases[name]++
+			bound[ref] = struct{}{}
+		}
+	}
+
+	ast.WalkExprs(module, func(expr *ast.Expr) bool {
+		if expr.IsAssignment() || expr.IsEquality() {
+			operands := expr.Operands()
+			bind(operands[0], operands[1], 3)
+			bind(operands[1], operands[0], 3)
+		}
+
+		if decl, ok := expr.Terms.(*ast.SomeDecl); ok {
+			for _, symbol := range decl.Symbols {
+				call, ok := symbol.Value.(ast.Call)
+				if !ok || (call[0].Value.Compare(ast.Member.Ref()) != 0 && call[0].Value.Compare(ast.MemberWithKey.Ref()) != 0) {
+					continue
+				}
+				// some [key,] value in collection
+				bind(call[len(call)-2], call[len(call)-1], 2)
+			}
+		}
+		return false
+	})
+
+	all := false
+	heads := make(map[ast.Var]int)
+	vars := make(map[ast.Var]int)
+	ast.WalkTerms(module, func(term *ast.Term) bool {
+		switch value := term.Value.(type) {
+		case ast.Var:
+			vars[value]++
+
+		case ast.Ref:
+			head, ok := value[0].Value.(ast.Var)
+			if !ok {
+				return false
+			}
+
+			if head.Equal(inputVar) {
+				heads[head]++
+				if _, ok := bound[term]; ok {
+					return false
+				}
+
+				field, ok := refKey(value, 1)
+				switch {
+				case ok && field == "repos":
+					field, ok = refKey(value, 3)
+				case ok && aggregate:
+					// org and timestamp of aggregate input do not depend on repository data
+					return false
+				}
+
+				if ok {
+					fields[field] = struct{}{}
+				} else {
+					all = true
+				}
+			} else if _, ok := aliases[head]; ok {
+				heads[head]++
+				if field, ok := refKey(value, 1); ok {
+					fields[field] = struct{}{}
+				} else {
+					all = true
+				}
+			}
+		}
+		return false
+	})
+
+	// input and tracked variables must appear only as head of reference or in binding. Otherwise they are used as a whole document
+	if heads[inputVar] < vars[inputVar] {
+		all = true
+	}
+	for v, n := range aliases {
+		if heads[v]+n < vars[v] {
+			all = true
+		}
+	}
+
+	return !all
+}
+
+// isReposRef returns true if term is `input.repos` (length 2) or `input.repos[x]` (length 3).
+func isReposRef(term *ast.Term, length int) bool {
+	ref, ok := term.Value.(ast.Ref)
+	if !ok || len(ref) != length || !ref.HasPrefix(ast.InputRootRef) {
+		return false
+	}
+	key, ok := refKey(ref, 1)
+	return ok && key == "repos"
+}
+
+func refKey(ref ast.Ref, idx int) (string, bool) {
+	if len(ref) <= idx {
+		return "", false
+	}
+	key, ok := ref[idx].Value.(ast.String)
+	if !ok {
+		return "", false
+	}
+	return string(key), true
+}
@@ -0,0 +1,156 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInputFields(t *testing.T) {
+	newFields := func(t *testing.T, modules map[string]string) model.InputFields {
+		fields, err := model.NewInputFields(modules, "github.aggregate", "github.org")
+		require.NoError(t, err)
+		return fields
+	}
+
+	t.Run("repository policy", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"repo.rego": `package github.repo
+fail[res] {
+	input.branches[_].protection == null
+	input.repo.private == false
+	res := {"category": "x", "message": "y"}
+}`,
+		})
+		require.NotNil(t, fields)
+		assert.True(t, fields.Has("branches"))
+		assert.True(t, fields.Has("repo"))
+		assert.False(t, fields.Has("hooks"))
+	})
+
+	t.Run("aggregate policy refers fields of repos", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"aggregate.rego": `package github.aggregate
+fail[res] {
+	input.repos[i].hooks[_].active
+	input.org.login == "x"
+	input.timestamp > 0
+	res := {"category": "x", "message": input.repos[i].repo.name}
+}`,
+		})
+		require.NotNil(t, fields)
+		assert.True(t, fields.Has("hooks"))
+		assert.True(t, fields.Has("repo"))
+		assert.False(t, fields.Has("org"))
+		assert.False(t, fields.Has("timestamp"))
+	})
+
+	t.Run("organization policy is ignored", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"org.rego": `package github.org
+fail[res] {
+	x := input
+	res := {"category": "x", "message": x.login}
+}`,
+		})
+		require.NotNil(t, fields)
+		assert.False(t, fields.Has("org"))
+	})
+
+	t.Run("input as a whole requires all fields", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"repo.rego": `package github.repo
+fail[res] {
+	x := input
+	res := {"category": "x", "message": x.repo.name}
+}`,
+		})
+		assert.Nil(t, fields)
+		assert.True(t, fields.Has("branches"))
+	})
+
+	t.Run("dynamic key requires all fields", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"repo.rego": `package github.repo
+fail[res] {
+	input[k]
+	res := {"category": "x", "message": k}
+}`,
+		})
+		assert.Nil(t, fields)
+	})
+
+	t.Run("variable bound to repository of aggregate is tracked", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"aggregate.rego": `package github.aggregate
+import future.keywords.in
+fail[res] {
+	r := input.repos[_]
+	r.branches[_].protected
+	res := {"category": "x", "message": "y", "repo": r.repo.full_name}
+}
+fail[res] {
+	some x in input.repos
+	count(x.hooks) > 0
+	res := {"category": "x", "message": "y"}
+}`,
+		})
+		require.NotNil(t, fields)
+		assert.True(t, fields.Has("branches"))
+		assert.True(t, fields.Has("repo"))
+		assert.True(t, fields.Has("hooks"))
+		assert.False(t, fields.Has("teams"))
+	})
+
+	t.Run("variable bound to repository used as a whole requires all fields", func(t *testing.T) {
+		fields := newFields(t, map[string]string{
+			"aggregate.rego": `package github.aggregate
+fail[res] {
+	public := [r | r := input.repos[_]; r.repo.private == false]
+	count(public) > 0
+	res := {"category": "x", "message": "y"}
+}`,
+		})
+		assert.Nil(t, fields)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := model.NewInputFields(map[string]string{"x.rego": "package"}, "github.aggregate", "github.org")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, types.ErrInvalidConfig))
+	})
+}
+
+func TestInputFields(t *testing.T) {
+	t.Run("nil means all fields", func(t *testing.T) {
+		var fields model.InputFields
+		assert.True(t, fields.All())
+		assert.True(t, fields.Any())
+		assert.True(t, fields.Has("branches"))
+		assert.True(t, fields.HasAny("branches", "hooks"))
+		assert.Nil(t, fields.Names())
+	})
+
+	t.Run("empty means no field", func(t *testing.T) {
+		fields := model.InputFields{}
+		assert.False(t, fields.All())
+		assert.False(t, fields.Any())
+		assert.False(t, fields.Has("branches"))
+		assert.False(t, fields.HasAny("branches", "hooks"))
+		assert.Empty(t, fields.Names())
+	})
+
+	t.Run("only listed fields", func(t *testing.T) {
+		fields := model.InputFields{"hooks": {}, "access": {}}
+		assert.False(t, fields.All())
+		assert.True(t, fields.Any())
+		assert.False(t, fields.Has("branches"))
+		assert.True(t, fields.HasAny("branches", "hooks"))
+		assert.False(t, fields.HasAny())
+		assert.Equal(t, []string{"access", "hooks"}, fields.Names())
+	})
+}
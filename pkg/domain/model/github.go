@@ -0,0 +1,189 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// GitHub API resources that are not supported by go-github v42. Field names follow the REST API response.
+
+// DeploymentBranchPolicy is a branch name pattern allowed to deploy to an environment.
+// https://docs.github.com/en/rest/deployments/branch-policies
+type DeploymentBranchPolicy struct {
+	ID     int64  `json:"id"`
+	NodeID string `json:"node_id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+// DeploymentProtectionRule is a custom deployment protection rule provided by a GitHub App.
+// https://docs.github.com/en/rest/deployments/protection-rules
+type DeploymentProtectionRule struct {
+	ID      int64                        `json:"id"`
+	NodeID  string                       `json:"node_id"`
+	Enabled bool                         `json:"enabled"`
+	App     *DeploymentProtectionRuleApp `json:"app"`
+}
+
+type DeploymentProtectionRuleApp struct {
+	ID             int64  `json:"id"`
+	Slug           string `json:"slug"`
+	IntegrationURL string `json:"integration_url"`
+	NodeID         string `json:"node_id"`
+}
+
+// SecurityAndAnalysis is `security_and_analysis` field of repository. go-github v42 does not support push protection and Dependabot security updates.
+// https://docs.github.com/en/rest/repos/repos#get-a-repository
+type SecurityAndAnalysis struct {
+	AdvancedSecurity             *SecurityAndAnalysisStatus `json:"advanced_security,omitempty"`
+	SecretScanning               *SecurityAndAnalysisStatus `json:"secret_scanning,omitempty"`
+	SecretScanningPushProtection *SecurityAndAnalysisStatus `json:"secret_scanning_push_protection,omitempty"`
+	DependabotSecurityUpdates    *SecurityAndAnalysisStatus `json:"dependabot_security_updates,omitempty"`
+}
+
+type SecurityAndAnalysisStatus struct {
+	Status string `json:"status"`
+}
+
+// SecurityAlert is a normalized open alert of Dependabot, code scanning or secret scanning.
+type SecurityAlert struct {
+	Number    int       `json:"number"`
+	Severity  string    `json:"severity"`
+	Summary   string    `json:"summary"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AppInstallation is a GitHub App installation in organization. Permissions is a map of permission name and access level ("read" or "write") to keep permissions that go-github v42 does not support.
+// https://docs.github.com/en/rest/orgs/orgs#list-app-installations-for-an-organization
+type AppInstallation struct {
+	ID                  int64             `json:"id"`
+	AppID               int64             `json:"app_id"`
+	AppSlug             string            `json:"app_slug"`
+	TargetType          string            `json:"target_type"`
+	RepositorySelection string            `json:"repository_selection"`
+	Permissions         map[string]string `json:"permissions"`
+	Events              []string          `json:"events"`
+	HTMLURL             string            `json:"html_url"`
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	SuspendedAt         *time.Time        `json:"suspended_at"`
+}
+
+// RunnerGroup is a self-hosted runner group of organization. go-github v42 does not support restricted_to_workflows and selected_workflows.
+// https://docs.github.com/en/rest/actions/self-hosted-runner-groups#list-self-hosted-runner-groups-for-an-organization
+type RunnerGroup struct {
+	ID                       int64    `json:"id"`
+	Name                     string   `json:"name"`
+	Visibility               string   `json:"visibility"`
+	Default                  bool     `json:"default"`
+	Inherited                bool     `json:"inherited"`
+	AllowsPublicRepositories bool     `json:"allows_public_repositories"`
+	RestrictedToWorkflows    bool     `json:"restricted_to_workflows"`
+	SelectedWorkflows        []string `json:"selected_workflows"`
+}
+
+// TagProtection is a tag protection rule of repository. The API is deprecated in favor of repository rulesets.
+// https://docs.github.com/en/rest/repos/tags#list-tag-protection-states-for-a-repository
+type TagProtection struct {
+	ID      int64  `json:"id"`
+	Pattern string `json:"pattern"`
+}
+
+// Ruleset is a repository ruleset including rulesets inherited from organization.
+// https://docs.github.com/en/rest/repos/rules#get-a-repository-ruleset
+type Ruleset struct {
+	ID          int64              `json:"id"`
+	Name        string             `json:"name"`
+	Target      string             `json:"target"`
+	SourceType  string             `json:"source_type"`
+	Source      string             `json:"source"`
+	Enforcement string             `json:"enforcement"`
+	Conditions  *RulesetConditions `json:"conditions"`
+	Rules       []*RulesetRule     `json:"rules"`
+}
+
+type RulesetConditions struct {
+	RefName *RulesetRefName `json:"ref_name,omitempty"`
+}
+
+type RulesetRefName struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+type RulesetRule struct {
+	Type string `json:"type"`
+}
+
+// Release is a release of repository with `immutable` field that go-github v42 does not support. Immutable is nil if the field is not responded.
+// https://docs.github.com/en/rest/releases/releases#list-releases
+type Release struct {
+	github.RepositoryRelease
+	Immutable *bool `json:"immutable,omitempty"`
+}
+
+// CustomProperty is a custom property definition of organization. DefaultValue is string, a list of string or nil.
+// https://docs.github.com/en/rest/orgs/custom-properties#get-all-custom-properties-for-an-organization
+type CustomProperty struct {
+	PropertyName     string      `json:"property_name"`
+	ValueType        string      `json:"value_type"`
+	Required         bool        `json:"required"`
+	DefaultValue     interface{} `json:"default_value"`
+	Description      string      `json:"description"`
+	AllowedValues    []string    `json:"allowed_values"`
+	ValuesEditableBy string      `json:"values_editable_by"`
+}
+
+// RepoCustomPropertyValues is custom property values of a repository.
+// https://docs.github.com/en/rest/orgs/custom-properties#list-custom-property-values-for-organization-repositories
+type RepoCustomPropertyValues struct {
+	RepositoryID       int64                  `json:"repository_id"`
+	RepositoryName     string                 `json:"repository_name"`
+	RepositoryFullName string                 `json:"repository_full_name"`
+	Properties         []*CustomPropertyValue `json:"properties"`
+}
+
+// CustomPropertyValue is a value of custom property. Value is string, a list of string (multi_select) or nil.
+type CustomPropertyValue struct {
+	PropertyName string      `json:"property_name"`
+	Value        interface{} `json:"value"`
+}
+
+// SBOM is a software bill of materials of repository in SPDX format exported from dependency graph.
+// https://docs.github.com/en/rest/dependency-graph/sboms
+type SBOM struct {
+	SPDXID        string              `json:"SPDXID"`
+	SPDXVersion   string              `json:"spdxVersion"`
+	Name          string              `json:"name"`
+	CreationInfo  *SBOMCreationInfo   `json:"creationInfo,omitempty"`
+	Packages      []*SBOMPackage      `json:"packages"`
+	Relationships []*SBOMRelationship `json:"relationships"`
+}
+
+type SBOMCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SBOMPackage struct {
+	SPDXID           string             `json:"SPDXID"`
+	Name             string             `json:"name"`
+	VersionInfo      string             `json:"versionInfo"`
+	LicenseConcluded string             `json:"licenseConcluded,omitempty"`
+	LicenseDeclared  string             `json:"licenseDeclared,omitempty"`
+	ExternalRefs     []*SBOMExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SBOMExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SBOMRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
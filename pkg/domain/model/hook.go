@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// RegoInputHook is a webhook with normalized config and summary of recent deliveries. Deliveries is nil if deliveries are not accessible.
+type RegoInputHook struct {
+	github.Hook
+	NormalizedConfig *HookConfig              `json:"normalized_config"`
+	Deliveries       *RegoInputHookDeliveries `json:"deliveries"`
+}
+
+// HookConfig is normalized `config` field of webhook. InsecureSSL is true if SSL verification of payload URL is disabled.
+type HookConfig struct {
+	URL              string `json:"url"`
+	URLScheme        string `json:"url_scheme"`
+	Host             string `json:"host"`
+	ContentType      string `json:"content_type"`
+	InsecureSSL      bool   `json:"insecure_ssl"`
+	SecretConfigured bool   `json:"secret_configured"`
+}
+
+// RegoInputHookDeliveries is a summary of recent deliveries. A delivery is regarded as failed if status code is not 2xx, including 0 that means the host was unreachable.
+type RegoInputHookDeliveries struct {
+	Total           int                    `json:"total"`
+	Failed          int                    `json:"failed"`
+	FailureRatio    float64                `json:"failure_ratio"`
+	StatusCodes     map[string]int         `json:"status_codes"`
+	LastDeliveredAt *time.Time             `json:"last_delivered_at"`
+	LastStatusCode  *int                   `json:"last_status_code"`
+	Recent          []*github.HookDelivery `json:"recent"`
+}
+
+// NewRegoInputHook normalizes config of hook and summarizes deliveries. deliveries must be ordered by newest first, and nil means deliveries are not accessible.
+func NewRegoInputHook(hook *github.Hook, deliveries []*github.HookDelivery) *RegoInputHook {
+	return &RegoInputHook{
+		Hook:             *hook,
+		NormalizedConfig: NewHookConfig(hook.Config),
+		Deliveries:       newRegoInputHookDeliveries(deliveries),
+	}
+}
+
+// NewHookConfig normalizes config of webhook. insecure_ssl is responded as "0" or "1" in string or number.
+func NewHookConfig(config map[string]interface{}) *HookConfig {
+	get := func(key string) string {
+		if v, ok := config[key]; ok && v != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return ""
+	}
+
+	cfg := &HookConfig{
+		URL:              get("url"),
+		ContentType:      get("content_type"),
+		SecretConfigured: get("secret") != "",
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = "form"
+	}
+
+	switch strings.ToLower(get("insecure_ssl")) {
+	case "1", "true":
+		cfg.InsecureSSL = true
+	}
+
+	if u, err := url.Parse(cfg.URL); err == nil {
+		cfg.URLScheme = strings.ToLower(u.Scheme)
+		cfg.Host = strings.ToLower(u.Hostname())
+	}
+
+	return cfg
+}
+
+func newRegoInputHookDeliveries(deliveries []*github.HookDelivery) *RegoInputHookDeliveries {
+	if deliveries == nil {
+		return nil
+	}
+
+	summary := &RegoInputHookDeliveries{
+		Total:       len(deliveries),
+		StatusCodes: make(map[string]int),
+		Recent:      deliveries,
+	}
+	for _, delivery := range deliveries {
+		code := delivery.GetStatusCode()
+		summary.StatusCodes[fmt.Sprintf("%d", code)]++
+		if code < 200 || 300 <= code {
+			summary.Failed++
+		}
+	}
+	if summary.Total > 0 {
+		summary.FailureRatio = float64(summary.Failed) / float64(summary.Total)
+
+		last := deliveries[0]
+		deliveredAt := last.GetDeliveredAt().Time
+		code := last.GetStatusCode()
+		summary.LastDeliveredAt = &deliveredAt
+		summary.LastStatusCode = &code
+	}
+
+	return summary
+}
@@ -0,0 +1,58 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHookConfig(t *testing.T) {
+	cfg := model.NewHookConfig(map[string]interface{}{
+		"url":          "HTTP://Example.com:8080/hook",
+		"insecure_ssl": "1",
+		"secret":       "********",
+	})
+	assert.Equal(t, "http", cfg.URLScheme)
+	assert.Equal(t, "example.com", cfg.Host)
+	assert.Equal(t, "form", cfg.ContentType)
+	assert.True(t, cfg.InsecureSSL)
+	assert.True(t, cfg.SecretConfigured)
+
+	cfg = model.NewHookConfig(map[string]interface{}{
+		"url":          "https://example.com/hook",
+		"content_type": "json",
+		"insecure_ssl": float64(0),
+	})
+	assert.Equal(t, "https", cfg.URLScheme)
+	assert.Equal(t, "json", cfg.ContentType)
+	assert.False(t, cfg.InsecureSSL)
+	assert.False(t, cfg.SecretConfigured)
+}
+
+func TestNewRegoInputHook(t *testing.T) {
+	hook := &github.Hook{ID: github.Int64(1)}
+
+	t.Run("deliveries", func(t *testing.T) {
+		v := model.NewRegoInputHook(hook, []*github.HookDelivery{
+			{StatusCode: github.Int(0)},
+			{StatusCode: github.Int(200)},
+			{StatusCode: github.Int(502)},
+			{StatusCode: github.Int(200)},
+		})
+		require.NotNil(t, v.Deliveries)
+		assert.Equal(t, 4, v.Deliveries.Total)
+		assert.Equal(t, 2, v.Deliveries.Failed)
+		assert.Equal(t, 0.5, v.Deliveries.FailureRatio)
+		assert.Equal(t, map[string]int{"0": 1, "200": 2, "502": 1}, v.Deliveries.StatusCodes)
+		assert.Equal(t, 0, *v.Deliveries.LastStatusCode)
+	})
+
+	t.Run("deliveries are not accessible", func(t *testing.T) {
+		v := model.NewRegoInputHook(hook, nil)
+		assert.Nil(t, v.Deliveries)
+		assert.NotNil(t, v.NormalizedConfig)
+	})
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/goerr"
+)
+
+// NewRegoInputProperties converts custom property values of a repository to a map of property name and value. Properties in schema that are not set for the repository have default value of the property (or nil).
+func NewRegoInputProperties(schema []*CustomProperty, values *RepoCustomPropertyValues) map[string]interface{} {
+	props := make(map[string]interface{})
+	for _, prop := range schema {
+		props[prop.PropertyName] = prop.DefaultValue
+	}
+	if values != nil {
+		for _, prop := range values.Properties {
+			props[prop.PropertyName] = prop.Value
+		}
+	}
+	return props
+}
+
+// PropertyFilter is a condition to select repositories by custom property value.
+type PropertyFilter struct {
+	Name  string
+	Value string
+}
+
+// ParsePropertyFilter parses filter in "name=value" format.
+func ParsePropertyFilter(s string) (*PropertyFilter, error) {
+	idx := strings.Index(s, "=")
+	if idx < 1 {
+		return nil, goerr.Wrap(types.ErrInvalidConfig, "property filter must be name=value format").With("filter", s)
+	}
+	return &PropertyFilter{
+		Name:  s[:idx],
+		Value: s[idx+1:],
+	}, nil
+}
+
+// Match returns true if the property value is equal to Value. For multi_select property, it returns true if one of values is equal to Value.
+func (x *PropertyFilter) Match(props map[string]interface{}) bool {
+	switch v := props[x.Name].(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, item := range v {
+			if fmt.Sprintf("%v", item) == x.Value {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, item := range v {
+			if item == x.Value {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprintf("%v", v) == x.Value
+	}
+}
@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegoInputProperties(t *testing.T) {
+	props := model.NewRegoInputProperties(
+		[]*model.CustomProperty{
+			{PropertyName: "tier", DefaultValue: "standard"},
+			{PropertyName: "owner-team"},
+			{PropertyName: "data-classification"},
+		},
+		&model.RepoCustomPropertyValues{
+			Properties: []*model.CustomPropertyValue{
+				{PropertyName: "data-classification", Value: []interface{}{"pii", "secret"}},
+			},
+		},
+	)
+	assert.Equal(t, map[string]interface{}{
+		"tier":                "standard",
+		"owner-team":          nil,
+		"data-classification": []interface{}{"pii", "secret"},
+	}, props)
+}
+
+func TestPropertyFilter(t *testing.T) {
+	props := map[string]interface{}{
+		"tier":                "critical",
+		"owner-team":          nil,
+		"data-classification": []interface{}{"pii", "secret"},
+	}
+
+	testCases := map[string]bool{
+		"tier=critical":           true,
+		"tier=standard":           false,
+		"owner-team=":             false,
+		"data-classification=pii": true,
+		"data-classification=n/a": false,
+		"unknown=x":               false,
+	}
+	for s, expected := range testCases {
+		filter, err := model.ParsePropertyFilter(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, filter.Match(props), s)
+	}
+
+	_, err := model.ParsePropertyFilter("=critical")
+	assert.Error(t, err)
+	_, err = model.ParsePropertyFilter("tier")
+	assert.Error(t, err)
+}
@@ -9,13 +9,121 @@ type RegoInputBranch struct {
 	Protection *github.Protection `json:"protection"`
 }
 
+// RegoInputTeam is a team with members. Members includes members of child teams.
+type RegoInputTeam struct {
+	github.Team
+	Members     []*github.User `json:"members"`
+	Maintainers []*github.User `json:"maintainers"`
+}
+
+type RegoInputEnvironment struct {
+	github.Environment
+	BranchPolicies        []*DeploymentBranchPolicy   `json:"branch_policies"`
+	CustomProtectionRules []*DeploymentProtectionRule `json:"custom_protection_rules"`
+}
+
+type RegoInputSecurity struct {
+	Analysis       *SecurityAndAnalysis `json:"analysis"`
+	Dependabot     *RegoInputAlerts     `json:"dependabot"`
+	CodeScanning   *RegoInputAlerts     `json:"code_scanning"`
+	SecretScanning *RegoInputAlerts     `json:"secret_scanning"`
+}
+
+// RegoInputAlerts is a summary of open security alerts. Severity has number of open alerts by severity.
+type RegoInputAlerts struct {
+	Open     int              `json:"open"`
+	Severity map[string]int   `json:"severity"`
+	Alerts   []*SecurityAlert `json:"alerts"`
+}
+
+// NewRegoInputAlerts summarizes alerts. It returns nil if alerts is nil because nil means the alert feature is not available for the repository.
+func NewRegoInputAlerts(alerts []*SecurityAlert) *RegoInputAlerts {
+	if alerts == nil {
+		return nil
+	}
+
+	summary := &RegoInputAlerts{
+		Open:     len(alerts),
+		Severity: make(map[string]int),
+		Alerts:   alerts,
+	}
+	for _, alert := range alerts {
+		severity := alert.Severity
+		if severity == "" {
+			severity = "unknown"
+		}
+		summary.Severity[severity]++
+	}
+
+	return summary
+}
+
+// RegoInputFile is a file in default branch. Content is set only if retrieving content is enabled.
+type RegoInputFile struct {
+	Exists  bool    `json:"exists"`
+	Size    int     `json:"size"`
+	SHA     string  `json:"sha"`
+	Content *string `json:"content,omitempty"`
+}
+
+// RegoInput is input data for repository policy. DirectCollaborators is not a part of input, and it is kept to rebuild Access with organization data of later audit.
 type RegoInput struct {
-	Repo          *github.Repository `json:"repo"`
-	Branches      []*RegoInputBranch `json:"branches"`
-	Collaborators []*github.User     `json:"collaborators"`
-	Hooks         []*github.Hook     `json:"hooks"`
-	Teams         []*github.Team     `json:"teams"`
-	Timestamp     int64              `json:"timestamp"`
+	Repo          *github.Repository        `json:"repo"`
+	Branches      []*RegoInputBranch        `json:"branches"`
+	Collaborators []*github.User            `json:"collaborators"`
+	Hooks         []*RegoInputHook          `json:"hooks"`
+	Teams         []*RegoInputTeam          `json:"teams"`
+	Environments  []*RegoInputEnvironment   `json:"environments"`
+	Security      *RegoInputSecurity        `json:"security"`
+	Files         map[string]*RegoInputFile `json:"files"`
+	CodeOwners    *CodeOwners               `json:"codeowners"`
+	Access        []*RegoInputAccess        `json:"access"`
+	Apps          []*AppInstallation        `json:"apps"`
+	Runners       *RegoInputRunners         `json:"runners"`
+	Activity      *RegoInputActivity        `json:"activity"`
+	Commits       *RegoInputCommits         `json:"commits"`
+	TagProtection *RegoInputTagProtection   `json:"tag_protection"`
+	Releases      []*RegoInputRelease       `json:"releases"`
+	Properties    map[string]interface{}    `json:"properties"`
+	RepoConfig    *RepoConfig               `json:"repo_config"`
+	Dependencies  *RegoInputDependencies    `json:"dependencies"`
+	Org           *RegoOrgInput             `json:"org,omitempty"`
+	Timestamp     int64                     `json:"timestamp"`
+
+	DirectCollaborators []*github.User `json:"-"`
+}
+
+// RegoInputOrgMember is a member of organization. TwoFactorDisabled is nil if 2FA status is not available.
+type RegoInputOrgMember struct {
+	github.User
+	Role              string `json:"role"`
+	TwoFactorDisabled *bool  `json:"2fa_disabled"`
+}
+
+// RegoOrgInput is input data for organization level policy. SAMLSSOEnabled is nil if it can not be determined. PropertyValues is custom property values of repositories keyed by full name of repository, and it is not a part of input because values are provided as input.properties of each repository.
+type RegoOrgInput struct {
+	Org                  *github.Organization                 `json:"org"`
+	Hooks                []*RegoInputHook                     `json:"hooks"`
+	ActionsPermissions   *github.ActionsPermissions           `json:"actions_permissions"`
+	ActionsAllowed       *github.ActionsAllowed               `json:"actions_allowed"`
+	SAMLSSOEnabled       *bool                                `json:"saml_sso_enabled"`
+	Members              []*RegoInputOrgMember                `json:"members"`
+	OutsideCollaborators []*github.User                       `json:"outside_collaborators"`
+	Invitations          []*github.Invitation                 `json:"invitations"`
+	Teams                []*RegoInputTeam                     `json:"teams"`
+	Installations        []*AppInstallation                   `json:"installations"`
+	Runners              []*github.Runner                     `json:"runners"`
+	RunnerGroups         []*RegoInputRunnerGroup              `json:"runner_groups"`
+	PropertySchema       []*CustomProperty                    `json:"property_schema"`
+	PropertyValues       map[string]*RepoCustomPropertyValues `json:"-"`
+	Timestamp            int64                                `json:"timestamp"`
+}
+
+// RegoAggregateInput is input data for aggregate policy evaluated with all repositories at once. Org field of each repository is omitted because it is same with Org.
+type RegoAggregateInput struct {
+	Repos     []*RegoInput  `json:"repos"`
+	Org       *RegoOrgInput `json:"org"`
+	Timestamp int64         `json:"timestamp"`
 }
 
 type RegoOutput struct {
@@ -26,3 +134,13 @@ type RegoFail struct {
 	Category string `json:"category"`
 	Message  string `json:"message"`
 }
+
+type RegoAggregateOutput struct {
+	Fail []*RegoAggregateFail `json:"fail"`
+}
+
+// RegoAggregateFail is a violation detected by aggregate policy. Repo is full name of repository (e.g. "my-org/my-repo") if the violation is related to a specific repository.
+type RegoAggregateFail struct {
+	RegoFail
+	Repo string `json:"repo"`
+}
@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegoInputAlerts(t *testing.T) {
+	t.Run("feature is not available", func(t *testing.T) {
+		assert.Nil(t, model.NewRegoInputAlerts(nil))
+	})
+
+	t.Run("no open alert", func(t *testing.T) {
+		assert.Equal(t, &model.RegoInputAlerts{
+			Open:     0,
+			Severity: map[string]int{},
+			Alerts:   []*model.SecurityAlert{},
+		}, model.NewRegoInputAlerts([]*model.SecurityAlert{}))
+	})
+
+	t.Run("alerts are counted by severity", func(t *testing.T) {
+		alerts := []*model.SecurityAlert{
+			{Number: 1, Severity: "critical"},
+			{Number: 2, Severity: "low"},
+			{Number: 3, Severity: "critical"},
+			{Number: 4},
+		}
+		summary := model.NewRegoInputAlerts(alerts)
+		assert.Equal(t, 4, summary.Open)
+		assert.Equal(t, map[string]int{"critical": 2, "low": 1, "unknown": 1}, summary.Severity)
+		assert.Equal(t, alerts, summary.Alerts)
+	})
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// RegoInputTagProtection is tag protection of a repository by both legacy tag protection and tag rulesets. Legacy is nil if the legacy API is not available. Patterns is a list of protected tag name patterns by legacy rules and active tag rulesets.
+type RegoInputTagProtection struct {
+	Legacy   []*TagProtection `json:"legacy"`
+	Rulesets []*Ruleset       `json:"rulesets"`
+	Patterns []string         `json:"patterns"`
+}
+
+// Enforcement of ruleset
+const RulesetEnforcementActive = "active"
+
+// NewRegoInputTagProtection merges protected tag patterns. Include conditions of rulesets are converted from ref name (e.g. "refs/tags/v*") to tag name pattern (e.g. "v*").
+func NewRegoInputTagProtection(legacy []*TagProtection, rulesets []*Ruleset) *RegoInputTagProtection {
+	patterns := make(map[string]struct{})
+	for _, rule := range legacy {
+		patterns[rule.Pattern] = struct{}{}
+	}
+	for _, ruleset := range rulesets {
+		if ruleset.Enforcement != RulesetEnforcementActive || ruleset.Conditions == nil || ruleset.Conditions.RefName == nil {
+			continue
+		}
+		for _, ref := range ruleset.Conditions.RefName.Include {
+			patterns[strings.TrimPrefix(ref, "refs/tags/")] = struct{}{}
+		}
+	}
+
+	resp := &RegoInputTagProtection{
+		Legacy:   legacy,
+		Rulesets: rulesets,
+		Patterns: []string{},
+	}
+	for pattern := range patterns {
+		resp.Patterns = append(resp.Patterns, pattern)
+	}
+	sort.Strings(resp.Patterns)
+
+	return resp
+}
+
+// RegoInputRelease is a release with heuristic flags about artifacts. Signed, Attested and Checksums are determined by file name of assets.
+type RegoInputRelease struct {
+	Release
+	Signed    bool `json:"signed"`
+	Attested  bool `json:"attested"`
+	Checksums bool `json:"checksums"`
+}
+
+var (
+	signatureAssetSuffixes   = []string{".sig", ".asc", ".minisig", ".sigstore", ".sigstore.json"}
+	attestationAssetSuffixes = []string{".intoto.jsonl", ".intoto.json", ".att"}
+	attestationAssetKeywords = []string{"provenance", "attestation"}
+	checksumAssetSuffixes    = []string{".sha256", ".sha512", ".sha256sum", ".sha512sum"}
+	checksumAssetKeywords    = []string{"checksums", "sha256sums", "sha512sums"}
+)
+
+func matchAssetName(name string, suffixes, keywords []string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	for _, keyword := range keywords {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRegoInputRelease checks assets of release.
+func NewRegoInputRelease(release *Release) *RegoInputRelease {
+	resp := &RegoInputRelease{Release: *release}
+	for _, asset := range release.Assets {
+		name := asset.GetName()
+		resp.Signed = resp.Signed || matchAssetName(name, signatureAssetSuffixes, nil)
+		resp.Attested = resp.Attested || matchAssetName(name, attestationAssetSuffixes, attestationAssetKeywords)
+		resp.Checksums = resp.Checksums || matchAssetName(name, checksumAssetSuffixes, checksumAssetKeywords)
+	}
+	return resp
+}
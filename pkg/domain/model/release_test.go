@@ -0,0 +1,50 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegoInputTagProtection(t *testing.T) {
+	v := model.NewRegoInputTagProtection(
+		[]*model.TagProtection{{Pattern: "v*"}},
+		[]*model.Ruleset{
+			{
+				Enforcement: model.RulesetEnforcementActive,
+				Conditions: &model.RulesetConditions{
+					RefName: &model.RulesetRefName{Include: []string{"refs/tags/v*", "refs/tags/release-*"}},
+				},
+			},
+			{
+				Enforcement: "evaluate",
+				Conditions: &model.RulesetConditions{
+					RefName: &model.RulesetRefName{Include: []string{"refs/tags/test-*"}},
+				},
+			},
+		},
+	)
+	assert.Equal(t, []string{"release-*", "v*"}, v.Patterns)
+}
+
+func TestNewRegoInputRelease(t *testing.T) {
+	newRelease := func(names ...string) *model.Release {
+		release := &model.Release{}
+		for _, name := range names {
+			release.Assets = append(release.Assets, &github.ReleaseAsset{Name: github.String(name)})
+		}
+		return release
+	}
+
+	v := model.NewRegoInputRelease(newRelease("app_linux_amd64.tar.gz", "app_linux_amd64.tar.gz.sig", "checksums.txt", "multiple.intoto.jsonl"))
+	assert.True(t, v.Signed)
+	assert.True(t, v.Attested)
+	assert.True(t, v.Checksums)
+
+	v = model.NewRegoInputRelease(newRelease("app_linux_amd64.tar.gz"))
+	assert.False(t, v.Signed)
+	assert.False(t, v.Attested)
+	assert.False(t, v.Checksums)
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// RepoConfigPath is location of per-repository configuration file of ghaudit
+const RepoConfigPath = ".github/ghaudit.yml"
+
+const (
+	RepoConfigSyntaxError      = "syntax_error"
+	RepoConfigInvalidExemption = "invalid_exemption"
+	RepoConfigExpiredExemption = "expired_exemption"
+)
+
+// RepoConfigErrorCategory is category of violation to report errors of per-repository configuration file
+const RepoConfigErrorCategory = "ghaudit.yml must be valid"
+
+// RepoConfig is per-repository configuration declared in the repository. Exemptions include only valid and not expired ones, and others are reported in Errors.
+type RepoConfig struct {
+	Path           string                 `json:"path"`
+	Content        string                 `json:"content"`
+	Classification string                 `json:"classification"`
+	Owners         []string               `json:"owners"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	Exemptions     []*Exemption           `json:"exemptions"`
+	Errors         []*RepoConfigError     `json:"errors"`
+}
+
+// Exemption exempts violations of the category from the repository. Message narrows the exemption to a violation with the same message if set. Expires is a date in "2006-01-02" format or RFC3339, and a date is valid through the day in UTC.
+type Exemption struct {
+	Category   string `json:"category"`
+	Message    string `json:"message,omitempty"`
+	Reason     string `json:"reason"`
+	Expires    string `json:"expires,omitempty"`
+	ApprovedBy string `json:"approved_by,omitempty"`
+}
+
+type RepoConfigError struct {
+	Kind      string     `json:"kind"`
+	Exemption *Exemption `json:"exemption,omitempty"`
+	Message   string     `json:"message"`
+}
+
+type repoConfigFile struct {
+	Classification string                 `json:"classification"`
+	Owners         []string               `json:"owners"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	Exemptions     []*Exemption           `json:"exemptions"`
+}
+
+// ParseRepoConfig parses per-repository configuration file. Syntax error and invalid or expired exemptions are reported in Errors.
+func ParseRepoConfig(path, content string, now time.Time) *RepoConfig {
+	cfg := &RepoConfig{
+		Path:       path,
+		Content:    content,
+		Exemptions: []*Exemption{},
+		Errors:     []*RepoConfigError{},
+	}
+
+	var file repoConfigFile
+	if err := yaml.Unmarshal([]byte(content), &file); err != nil {
+		cfg.Errors = append(cfg.Errors, &RepoConfigError{
+			Kind:    RepoConfigSyntaxError,
+			Message: err.Error(),
+		})
+		return cfg
+	}
+	cfg.Classification = file.Classification
+	cfg.Owners = file.Owners
+	cfg.Metadata = file.Metadata
+
+	for _, exemption := range file.Exemptions {
+		if exemption == nil {
+			continue
+		}
+		if err := exemption.validate(now); err != nil {
+			cfg.Errors = append(cfg.Errors, err)
+			continue
+		}
+		cfg.Exemptions = append(cfg.Exemptions, exemption)
+	}
+
+	return cfg
+}
+
+func parseExpires(expires string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", expires); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return time.Parse(time.RFC3339, expires)
+}
+
+func (x *Exemption) validate(now time.Time) *RepoConfigError {
+	invalid := func(msg string) *RepoConfigError {
+		return &RepoConfigError{
+			Kind:      RepoConfigInvalidExemption,
+			Exemption: x,
+			Message:   msg,
+		}
+	}
+
+	if x.Category == "" {
+		return invalid("category is required")
+	}
+	if x.Reason == "" {
+		return invalid(fmt.Sprintf("reason is required for exemption of '%s'", x.Category))
+	}
+	if x.Expires != "" {
+		expires, err := parseExpires(x.Expires)
+		if err != nil {
+			return invalid(fmt.Sprintf("expires of '%s' must be YYYY-MM-DD or RFC3339 format: %s", x.Category, x.Expires))
+		}
+		if !now.Before(expires) {
+			return &RepoConfigError{
+				Kind:      RepoConfigExpiredExemption,
+				Exemption: x,
+				Message:   fmt.Sprintf("exemption of '%s' expired at %s", x.Category, x.Expires),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Exempt returns the exemption that matches fail. It returns nil if fail is not exempted.
+func (x *RepoConfig) Exempt(fail *RegoFail) *Exemption {
+	if x == nil {
+		return nil
+	}
+	for _, exemption := range x.Exemptions {
+		if exemption.Category != fail.Category {
+			continue
+		}
+		if exemption.Message != "" && exemption.Message != fail.Message {
+			continue
+		}
+		return exemption
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepoConfig(t *testing.T) {
+	content := `classification: critical
+owners:
+  - "@my-org/security"
+metadata:
+  service: payment
+exemptions:
+  - category: default branch must be protected
+    reason: mirror repository
+    expires: 2022-03-01
+  - category: Outside collaborator must not have write access
+    message: bob has write access
+    reason: contractor
+  - category: no reason
+  - category: expired
+    reason: temporary
+    expires: 2022-02-28
+  - category: broken date
+    reason: temporary
+    expires: next month
+`
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	cfg := model.ParseRepoConfig(model.RepoConfigPath, content, now)
+
+	assert.Equal(t, "critical", cfg.Classification)
+	assert.Equal(t, []string{"@my-org/security"}, cfg.Owners)
+	assert.Equal(t, "payment", cfg.Metadata["service"])
+
+	require.Len(t, cfg.Exemptions, 2)
+	require.Len(t, cfg.Errors, 3)
+	assert.Equal(t, model.RepoConfigInvalidExemption, cfg.Errors[0].Kind)
+	assert.Equal(t, model.RepoConfigExpiredExemption, cfg.Errors[1].Kind)
+	assert.Equal(t, model.RepoConfigInvalidExemption, cfg.Errors[2].Kind)
+
+	t.Run("exempt", func(t *testing.T) {
+		assert.NotNil(t, cfg.Exempt(&model.RegoFail{Category: "default branch must be protected", Message: "default branch is main"}))
+		assert.NotNil(t, cfg.Exempt(&model.RegoFail{Category: "Outside collaborator must not have write access", Message: "bob has write access"}))
+		assert.Nil(t, cfg.Exempt(&model.RegoFail{Category: "Outside collaborator must not have write access", Message: "carol has write access"}))
+		assert.Nil(t, cfg.Exempt(&model.RegoFail{Category: "expired"}))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		cfg := model.ParseRepoConfig(model.RepoConfigPath, "exemptions: [", now)
+		require.Len(t, cfg.Errors, 1)
+		assert.Equal(t, model.RepoConfigSyntaxError, cfg.Errors[0].Kind)
+		assert.Nil(t, cfg.Exempt(&model.RegoFail{Category: "x"}))
+	})
+}
@@ -0,0 +1,61 @@
+package model
+
+import "github.com/google/go-github/v42/github"
+
+// Visibility of runner group
+const (
+	RunnerGroupVisibilityAll      = "all"
+	RunnerGroupVisibilitySelected = "selected"
+	RunnerGroupVisibilityPrivate  = "private"
+)
+
+// RegoInputRunnerGroup is a runner group with its runners. Repositories is a list of full name of repositories that can use the group, and it is set only if visibility is "selected".
+type RegoInputRunnerGroup struct {
+	RunnerGroup
+	Runners      []*github.Runner `json:"runners"`
+	Repositories []string         `json:"repositories"`
+}
+
+// RegoInputRunners is self-hosted runners available to a repository. Repository is nil if runners of the repository are not accessible.
+type RegoInputRunners struct {
+	Repository []*github.Runner        `json:"repository"`
+	Groups     []*RegoInputRunnerGroup `json:"groups"`
+}
+
+// AccessibleBy returns true if workflows of repo can use runners of the group.
+func (x *RegoInputRunnerGroup) AccessibleBy(repo *github.Repository) bool {
+	if !repo.GetPrivate() && !x.AllowsPublicRepositories {
+		return false
+	}
+
+	switch x.Visibility {
+	case RunnerGroupVisibilityAll:
+		return true
+	case RunnerGroupVisibilityPrivate:
+		return repo.GetPrivate()
+	case RunnerGroupVisibilitySelected:
+		for _, name := range x.Repositories {
+			if name == repo.GetFullName() {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// NewRegoInputRunners builds runners available to repo from runners of the repository and runner groups of the organization.
+func NewRegoInputRunners(repo *github.Repository, repoRunners []*github.Runner, org *RegoOrgInput) *RegoInputRunners {
+	runners := &RegoInputRunners{
+		Repository: repoRunners,
+	}
+	if org != nil {
+		for _, group := range org.RunnerGroups {
+			if group.AccessibleBy(repo) {
+				runners.Groups = append(runners.Groups, group)
+			}
+		}
+	}
+	return runners
+}
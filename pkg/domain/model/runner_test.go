@@ -0,0 +1,50 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegoInputRunners(t *testing.T) {
+	org := &model.RegoOrgInput{
+		RunnerGroups: []*model.RegoInputRunnerGroup{
+			{RunnerGroup: model.RunnerGroup{Name: "default", Visibility: model.RunnerGroupVisibilityAll}},
+			{RunnerGroup: model.RunnerGroup{Name: "public", Visibility: model.RunnerGroupVisibilityAll, AllowsPublicRepositories: true}},
+			{RunnerGroup: model.RunnerGroup{Name: "private", Visibility: model.RunnerGroupVisibilityPrivate, AllowsPublicRepositories: true}},
+			{
+				RunnerGroup:  model.RunnerGroup{Name: "selected", Visibility: model.RunnerGroupVisibilitySelected},
+				Repositories: []string{"my-org/private-repo"},
+			},
+		},
+	}
+
+	groupNames := func(runners *model.RegoInputRunners) []string {
+		var names []string
+		for _, g := range runners.Groups {
+			names = append(names, g.Name)
+		}
+		return names
+	}
+
+	t.Run("private repository", func(t *testing.T) {
+		repo := &github.Repository{FullName: github.String("my-org/private-repo"), Private: github.Bool(true)}
+		runners := model.NewRegoInputRunners(repo, nil, org)
+		assert.Equal(t, []string{"default", "public", "private", "selected"}, groupNames(runners))
+	})
+
+	t.Run("public repository", func(t *testing.T) {
+		repo := &github.Repository{FullName: github.String("my-org/public-repo"), Private: github.Bool(false)}
+		runners := model.NewRegoInputRunners(repo, nil, org)
+		assert.Equal(t, []string{"public"}, groupNames(runners))
+	})
+
+	t.Run("without organization data", func(t *testing.T) {
+		repo := &github.Repository{FullName: github.String("my-org/public-repo")}
+		runners := model.NewRegoInputRunners(repo, []*github.Runner{{Name: github.String("r1")}}, nil)
+		assert.Len(t, runners.Repository, 1)
+		assert.Len(t, runners.Groups, 0)
+	})
+}
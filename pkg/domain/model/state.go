@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/go-github/v42/github"
+)
+
+// AuditState is state of the last audit to retrieve only repositories changed since then. Settings is a signature of options affecting collected data, and state with different settings is not reused.
+type AuditState struct {
+	Owner         string                `json:"owner"`
+	Settings      string                `json:"settings"`
+	FullRefreshAt time.Time             `json:"full_refresh_at"`
+	Repos         map[string]*RepoState `json:"repos"`
+}
+
+// RepoState is timestamps of a repository when its data was collected. Fields is names of collected input fields, and nil means all fields.
+type RepoState struct {
+	PushedAt    time.Time `json:"pushed_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CollectedAt time.Time `json:"collected_at"`
+	Fields      []string  `json:"fields"`
+}
+
+// NewAuditState creates empty state.
+func NewAuditState(owner, settings string, fullRefreshAt time.Time) *AuditState {
+	return &AuditState{
+		Owner:         owner,
+		Settings:      settings,
+		FullRefreshAt: fullRefreshAt,
+		Repos:         make(map[string]*RepoState),
+	}
+}
+
+// NeedsFullRefresh returns true if state can not be reused: no state, different owner or settings, or the last full refresh is older than refreshAge. Zero refreshAge never expires state.
+func (x *AuditState) NeedsFullRefresh(owner, settings string, refreshAge time.Duration, now time.Time) bool {
+	if x == nil || x.Owner != owner || x.Settings != settings {
+		return true
+	}
+	return 0 < refreshAge && refreshAge < now.Sub(x.FullRefreshAt)
+}
+
+// Changed returns true if repo has been pushed or updated since the last collection, or fields required now were not collected.
+func (x *AuditState) Changed(repo *github.Repository, fields InputFields) bool {
+	if x == nil {
+		return true
+	}
+	state, ok := x.Repos[repo.GetFullName()]
+	if !ok {
+		return true
+	}
+
+	if !state.PushedAt.Equal(repo.GetPushedAt().Time) || !state.UpdatedAt.Equal(repo.GetUpdatedAt().Time) {
+		return true
+	}
+
+	if state.Fields == nil {
+		return false
+	}
+	if fields.All() {
+		return true
+	}
+	collected := make(map[string]struct{})
+	for _, name := range state.Fields {
+		collected[name] = struct{}{}
+	}
+	for name := range fields {
+		if _, ok := collected[name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Update records repo collected with fields at now.
+func (x *AuditState) Update(repo *github.Repository, fields InputFields, now time.Time) {
+	x.Repos[repo.GetFullName()] = &RepoState{
+		PushedAt:    repo.GetPushedAt().Time,
+		UpdatedAt:   repo.GetUpdatedAt().Time,
+		CollectedAt: now,
+		Fields:      fields.Names(),
+	}
+}
@@ -0,0 +1,57 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditState(t *testing.T) {
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &github.Repository{
+		FullName:  github.String("example/blue"),
+		PushedAt:  &github.Timestamp{Time: now.Add(-time.Hour)},
+		UpdatedAt: &github.Timestamp{Time: now.Add(-2 * time.Hour)},
+	}
+
+	t.Run("full refresh", func(t *testing.T) {
+		var empty *model.AuditState
+		assert.True(t, empty.NeedsFullRefresh("example", "s1", time.Hour, now))
+
+		state := model.NewAuditState("example", "s1", now.Add(-time.Hour))
+		assert.False(t, state.NeedsFullRefresh("example", "s1", 2*time.Hour, now))
+		assert.False(t, state.NeedsFullRefresh("example", "s1", 0, now))
+		assert.True(t, state.NeedsFullRefresh("example", "s1", 30*time.Minute, now))
+		assert.True(t, state.NeedsFullRefresh("other", "s1", 2*time.Hour, now))
+		assert.True(t, state.NeedsFullRefresh("example", "s2", 2*time.Hour, now))
+	})
+
+	t.Run("changed repository", func(t *testing.T) {
+		state := model.NewAuditState("example", "s1", now)
+		assert.True(t, state.Changed(repo, nil))
+
+		state.Update(repo, nil, now)
+		assert.False(t, state.Changed(repo, nil))
+		assert.False(t, state.Changed(repo, model.InputFields{"branches": {}}))
+
+		pushed := *repo
+		pushed.PushedAt = &github.Timestamp{Time: now}
+		assert.True(t, state.Changed(&pushed, nil))
+
+		updated := *repo
+		updated.UpdatedAt = &github.Timestamp{Time: now}
+		assert.True(t, state.Changed(&updated, nil))
+	})
+
+	t.Run("fields not collected", func(t *testing.T) {
+		state := model.NewAuditState("example", "s1", now)
+		state.Update(repo, model.InputFields{"branches": {}, "hooks": {}}, now)
+
+		assert.False(t, state.Changed(repo, model.InputFields{"branches": {}}))
+		assert.True(t, state.Changed(repo, model.InputFields{"branches": {}, "teams": {}}))
+		assert.True(t, state.Changed(repo, nil))
+	})
+}
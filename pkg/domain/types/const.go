@@ -1,23 +1,47 @@
 package types
 
 const (
-	EnvOwner           = "GHAUDIT_OWNER"
-	EnvAppID           = "GHAUDIT_APP_ID"
-	EnvInstallID       = "GHAUDIT_INSTALL_ID"
-	EnvPrivateKeyFile  = "GHAUDIT_PRIVATE_KEY_FILE"
-	EnvPrivateKeyData  = "GHAUDIT_PRIVATE_KEY_DATA"
-	EnvPolicy          = "GHAUDIT_POLICY"
-	EnvPackage         = "GHAUDIT_PACKAGE"
-	EnvURL             = "GHAUDIT_URL"
-	EnvHeader          = "GHAUDIT_HEADER"
-	EnvLogFormat       = "GHAUDIT_LOG_FORMAT"
-	EnvLogLevel        = "GHAUDIT_LOG_LEVEL"
-	EnvSlackWebhookURL = "GHAUDIT_SLACK_WEBHOOK"
-	EnvFail            = "GHAUDIT_FAIL"
-	EnvSkipArchived    = "GHAUDIT_SKIP_ARCHIVED"
-	EnvThread          = "GHAUDIT_THREAD"
-	EnvLimit           = "GHAUDIT_LIMIT"
-	EnvDumpDir         = "GHAUDIT_DUMP"
-	EnvLoadDir         = "GHAUDIT_LOAD"
-	EnvSlackWebhook    = "GHAUDIT_SLACK_WEBHOOK"
+	EnvOwner            = "GHAUDIT_OWNER"
+	EnvAppID            = "GHAUDIT_APP_ID"
+	EnvInstallID        = "GHAUDIT_INSTALL_ID"
+	EnvPrivateKeyFile   = "GHAUDIT_PRIVATE_KEY_FILE"
+	EnvPrivateKeyData   = "GHAUDIT_PRIVATE_KEY_DATA"
+	EnvPolicy           = "GHAUDIT_POLICY"
+	EnvPackage          = "GHAUDIT_PACKAGE"
+	EnvOrgPackage       = "GHAUDIT_ORG_PACKAGE"
+	EnvOrgURL           = "GHAUDIT_ORG_URL"
+	EnvAggregatePackage = "GHAUDIT_AGGREGATE_PACKAGE"
+	EnvAggregateURL     = "GHAUDIT_AGGREGATE_URL"
+	EnvURL              = "GHAUDIT_URL"
+	EnvHeader           = "GHAUDIT_HEADER"
+	EnvLogFormat        = "GHAUDIT_LOG_FORMAT"
+	EnvLogLevel         = "GHAUDIT_LOG_LEVEL"
+	EnvSlackWebhookURL  = "GHAUDIT_SLACK_WEBHOOK"
+	EnvFail             = "GHAUDIT_FAIL"
+	EnvSkipArchived     = "GHAUDIT_SKIP_ARCHIVED"
+	EnvThread           = "GHAUDIT_THREAD"
+	EnvConcurrency      = "GHAUDIT_CONCURRENCY"
+	EnvMaxConcurrency   = "GHAUDIT_MAX_CONCURRENCY"
+	EnvCacheDir         = "GHAUDIT_CACHE_DIR"
+	EnvCacheTTL         = "GHAUDIT_CACHE_TTL"
+	EnvLimit            = "GHAUDIT_LIMIT"
+	EnvDumpDir          = "GHAUDIT_DUMP"
+	EnvLoadDir          = "GHAUDIT_LOAD"
+	EnvSlackWebhook     = "GHAUDIT_SLACK_WEBHOOK"
+	EnvFile             = "GHAUDIT_FILE"
+	EnvFileContent      = "GHAUDIT_FILE_CONTENT"
+	EnvCommits          = "GHAUDIT_COMMITS"
+	EnvPropertyFilter   = "GHAUDIT_PROPERTY_FILTER"
+	EnvCollectAll       = "GHAUDIT_COLLECT_ALL"
+	EnvGraphQL          = "GHAUDIT_GRAPHQL"
+	EnvStateDir         = "GHAUDIT_STATE_DIR"
+	EnvFullRefresh      = "GHAUDIT_FULL_REFRESH"
+	EnvRefreshAge       = "GHAUDIT_REFRESH_AGE"
+	EnvWorkDir          = "GHAUDIT_WORK_DIR"
+	EnvResume           = "GHAUDIT_RESUME"
+)
+
+const (
+	// DumpOrgDir is sub directory name in dump directory to save organization data
+	DumpOrgDir = "org"
 )
@@ -7,9 +7,11 @@ import (
 )
 
 type Clients struct {
-	ghapp  githubapp.Client
-	policy opac.Client
-	slack  notify.SlackClient
+	ghapp           githubapp.Client
+	policy          opac.Client
+	orgPolicy       opac.Client
+	aggregatePolicy opac.Client
+	slack           notify.SlackClient
 }
 
 func New(options ...Option) *Clients {
@@ -20,9 +22,11 @@ func New(options ...Option) *Clients {
 	return clients
 }
 
-func (x *Clients) GitHubApp() githubapp.Client { return x.ghapp }
-func (x *Clients) Policy() opac.Client         { return x.policy }
-func (x *Clients) Slack() notify.SlackClient   { return x.slack }
+func (x *Clients) GitHubApp() githubapp.Client  { return x.ghapp }
+func (x *Clients) Policy() opac.Client          { return x.policy }
+func (x *Clients) OrgPolicy() opac.Client       { return x.orgPolicy }
+func (x *Clients) AggregatePolicy() opac.Client { return x.aggregatePolicy }
+func (x *Clients) Slack() notify.SlackClient    { return x.slack }
 
 type Option func(c *Clients)
 
@@ -38,6 +42,18 @@ func WithPolicy(client opac.Client) Option {
 	}
 }
 
+func WithOrgPolicy(client opac.Client) Option {
+	return func(c *Clients) {
+		c.orgPolicy = client
+	}
+}
+
+func WithAggregatePolicy(client opac.Client) Option {
+	return func(c *Clients) {
+		c.aggregatePolicy = client
+	}
+}
+
 func WithSlack(client notify.SlackClient) Option {
 	return func(c *Clients) {
 		c.slack = client
@@ -0,0 +1,182 @@
+package githubapp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+)
+
+// CacheTransport is http.RoundTripper to cache responses of GET requests on disk. A cached response is revalidated by conditional request with If-None-Match (ETag) or If-Modified-Since (Last-Modified) and 304 Not Modified response is replaced with the cached one. 304 responses do not count against rate limit of GitHub API. Cached responses not validated for TTL are discarded. Zero TTL keeps them forever.
+type CacheTransport struct {
+	base      http.RoundTripper
+	dir       string
+	namespace string
+	ttl       time.Duration
+}
+
+type cacheEntry struct {
+	URL         string      `json:"url"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+	ValidatedAt time.Time   `json:"validated_at"`
+}
+
+// NewCacheTransport creates CacheTransport. namespace identifies credentials of requests (e.g. GitHub App installation) so that responses cached for an installation are not returned to another one sharing dir.
+func NewCacheTransport(base http.RoundTripper, dir, namespace string, ttl time.Duration) (*CacheTransport, error) {
+	// cached responses may include data of private repositories
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, goerr.Wrap(err).With("dir", dir)
+	}
+
+	return &CacheTransport{
+		base:      base,
+		dir:       dir,
+		namespace: namespace,
+		ttl:       ttl,
+	}, nil
+}
+
+func (x *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("Range") != "" {
+		return x.base.RoundTrip(req)
+	}
+
+	path := x.path(req)
+	entry := x.load(path)
+
+	r := req
+	if entry != nil {
+		r = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			r.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := x.base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		// keep latest headers such as rate limit
+		for key, values := range resp.Header {
+			if key != "Content-Length" {
+				entry.Header[key] = values
+			}
+		}
+		entry.ValidatedAt = time.Now()
+		x.store(path, entry)
+
+		utils.Logger.With("url", req.URL.String()).Trace("use cached response")
+		return entry.response(req), nil
+
+	case resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""):
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		header := resp.Header.Clone()
+		header.Del("Content-Length")
+		x.store(path, &cacheEntry{
+			URL:         req.URL.String(),
+			StatusCode:  resp.StatusCode,
+			Header:      header,
+			Body:        body,
+			ValidatedAt: time.Now(),
+		})
+	}
+
+	return resp, nil
+}
+
+// path returns file path of cache entry. Accept header is a part of key because media type changes response. Authorization header is not used because installation access token changes every hour.
+func (x *CacheTransport) path(req *http.Request) string {
+	hash := sha256.Sum256([]byte(x.namespace + "\n" + req.URL.String() + "\n" + req.Header.Get("Accept")))
+	return filepath.Join(x.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+// load returns cached entry. It returns nil if the entry does not exist, is broken or is expired.
+func (x *CacheTransport) load(path string) *cacheEntry {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		utils.Logger.With("path", path).With("error", err.Error()).Debug("ignore broken cache")
+		return nil
+	}
+	if 0 < x.ttl && x.ttl < time.Since(entry.ValidatedAt) {
+		_ = os.Remove(path)
+		return nil
+	}
+
+	return &entry
+}
+
+// store saves entry. Failure of saving is not an error of request.
+func (x *CacheTransport) store(path string, entry *cacheEntry) {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		utils.Logger.With("url", entry.URL).With("error", err.Error()).Warn("failed to encode cache")
+		return
+	}
+
+	// write and rename not to leave partial file by concurrent requests
+	tmp, err := os.CreateTemp(x.dir, "tmp-*")
+	if err != nil {
+		utils.Logger.With("dir", x.dir).With("error", err.Error()).Warn("failed to save cache")
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		utils.Logger.With("path", tmp.Name()).With("error", err.Error()).Warn("failed to save cache")
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		utils.Logger.With("path", tmp.Name()).With("error", err.Error()).Warn("failed to save cache")
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		utils.Logger.With("path", path).With("error", err.Error()).Warn("failed to save cache")
+	}
+}
+
+func (x *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", x.StatusCode, http.StatusText(x.StatusCode)),
+		StatusCode:    x.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        x.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(x.Body)),
+		ContentLength: int64(len(x.Body)),
+		Request:       req,
+	}
+}
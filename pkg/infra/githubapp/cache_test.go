@@ -0,0 +1,130 @@
+package githubapp_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheTransport(t *testing.T) {
+	// etagHandler responds 304 if If-None-Match matches and records the header
+	etagHandler := func(conditions *[]string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			*conditions = append(*conditions, r.Header.Get("If-None-Match"))
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte(`{"name":"blue"}`))
+		}
+	}
+
+	get := func(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	t.Run("revalidate cached response by ETag", func(t *testing.T) {
+		var conditions []string
+		srv, _ := newTestServer(t, etagHandler(&conditions))
+
+		tr, err := githubapp.NewCacheTransport(http.DefaultTransport, t.TempDir(), "1/1", time.Hour)
+		require.NoError(t, err)
+		client := &http.Client{Transport: tr}
+
+		resp, body := get(t, client, srv.URL+"/repos/x")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"name":"blue"}`, body)
+
+		resp, body = get(t, client, srv.URL+"/repos/x")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"name":"blue"}`, body)
+		assert.Equal(t, `"v1"`, resp.Header.Get("ETag"))
+		assert.Equal(t, "4999", resp.Header.Get("X-RateLimit-Remaining"))
+
+		assert.Equal(t, []string{"", `"v1"`}, conditions)
+	})
+
+	t.Run("cache is kept in directory across transports", func(t *testing.T) {
+		var conditions []string
+		srv, _ := newTestServer(t, etagHandler(&conditions))
+		dir := t.TempDir()
+
+		for i := 0; i < 2; i++ {
+			tr, err := githubapp.NewCacheTransport(http.DefaultTransport, dir, "1/1", time.Hour)
+			require.NoError(t, err)
+			_, body := get(t, &http.Client{Transport: tr}, srv.URL+"/repos/x")
+			assert.Equal(t, `{"name":"blue"}`, body)
+		}
+		assert.Equal(t, []string{"", `"v1"`}, conditions)
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 1)
+	})
+
+	t.Run("expired cache is not used", func(t *testing.T) {
+		var conditions []string
+		srv, _ := newTestServer(t, etagHandler(&conditions))
+
+		tr, err := githubapp.NewCacheTransport(http.DefaultTransport, t.TempDir(), "1/1", time.Nanosecond)
+		require.NoError(t, err)
+		client := &http.Client{Transport: tr}
+
+		get(t, client, srv.URL+"/repos/x")
+		time.Sleep(time.Millisecond)
+		resp, body := get(t, client, srv.URL+"/repos/x")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"name":"blue"}`, body)
+		assert.Equal(t, []string{"", ""}, conditions)
+	})
+
+	t.Run("response without validator and non-GET request are not cached", func(t *testing.T) {
+		srv, count := newTestServer(t, respond(http.StatusOK, nil))
+		dir := t.TempDir()
+
+		tr, err := githubapp.NewCacheTransport(http.DefaultTransport, dir, "1/1", time.Hour)
+		require.NoError(t, err)
+		client := &http.Client{Transport: tr}
+
+		get(t, client, srv.URL+"/repos/x")
+		resp, err := client.Post(srv.URL+"/graphql", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, 2, *count)
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("cache is not shared between namespaces", func(t *testing.T) {
+		var conditions []string
+		srv, _ := newTestServer(t, etagHandler(&conditions))
+		dir := t.TempDir()
+
+		for _, namespace := range []string{"1/1", "1/2"} {
+			tr, err := githubapp.NewCacheTransport(http.DefaultTransport, dir, namespace, time.Hour)
+			require.NoError(t, err)
+			_, body := get(t, &http.Client{Transport: tr}, srv.URL+"/repos/x")
+			assert.Equal(t, `{"name":"blue"}`, body)
+		}
+		assert.Equal(t, []string{"", ""}, conditions)
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 2)
+	})
+}
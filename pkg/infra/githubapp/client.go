@@ -1,38 +1,174 @@
 package githubapp
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v42/github"
 	"github.com/m-mizutani/goerr"
 
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
 	"github.com/m-mizutani/ghaudit/pkg/domain/types"
 	"github.com/m-mizutani/ghaudit/pkg/utils"
 )
 
 type Client interface {
 	GetRepos(ctx *types.Context, owner string) ([]*github.Repository, error)
+
+	// Organization
+	// GetOrganization returns nil if org is not an organization or not accessible
+	GetOrganization(ctx *types.Context, org string) (*github.Organization, error)
+	GetOrgHooks(ctx *types.Context, org string) ([]*github.Hook, error)
+	// GetOrgHookDeliveries returns recent deliveries of the hook ordered by newest first. It returns nil if deliveries are not accessible.
+	GetOrgHookDeliveries(ctx *types.Context, org string, hookID int64) ([]*github.HookDelivery, error)
+	GetOrgActionsPermissions(ctx *types.Context, org string) (*github.ActionsPermissions, error)
+	GetOrgActionsAllowed(ctx *types.Context, org string) (*github.ActionsAllowed, error)
+	// GetOrgSAMLSSOEnabled returns true if SAML SSO is enabled, and nil if it can not be determined
+	GetOrgSAMLSSOEnabled(ctx *types.Context, org string) (*bool, error)
+	// GetOrgMembers returns members having role ("admin" or "member"). It returns nil if members are not accessible.
+	GetOrgMembers(ctx *types.Context, org, role string) ([]*github.User, error)
+	// GetOrgMembersWithout2FA returns nil if the installation is not allowed to filter members by 2FA status
+	GetOrgMembersWithout2FA(ctx *types.Context, org string) ([]*github.User, error)
+	GetOutsideCollaborators(ctx *types.Context, org string) ([]*github.User, error)
+	GetOrgInvitations(ctx *types.Context, org string) ([]*github.Invitation, error)
+	GetOrgTeams(ctx *types.Context, org string) ([]*github.Team, error)
+	GetOrgInstallations(ctx *types.Context, org string) ([]*model.AppInstallation, error)
+	// GetTeamMembers returns members having role ("all", "member" or "maintainer") of the team. It returns nil if members are not accessible.
+	GetTeamMembers(ctx *types.Context, org, slug, role string) ([]*github.User, error)
+	// Custom property methods return nil if custom properties are not accessible
+	GetOrgPropertySchema(ctx *types.Context, org string) ([]*model.CustomProperty, error)
+	GetOrgPropertyValues(ctx *types.Context, org string) ([]*model.RepoCustomPropertyValues, error)
+	// Runner methods return nil if self-hosted runners are not accessible
+	GetOrgRunners(ctx *types.Context, org string) ([]*github.Runner, error)
+	GetOrgRunnerGroups(ctx *types.Context, org string) ([]*model.RunnerGroup, error)
+	GetRunnerGroupRunners(ctx *types.Context, org string, groupID int64) ([]*github.Runner, error)
+	GetRunnerGroupRepos(ctx *types.Context, org string, groupID int64) ([]*github.Repository, error)
+
+	// Repository
 	GetBranches(ctx *types.Context, owner, repo string) ([]*github.Branch, error)
 	GetBranchProtection(ctx *types.Context, owner, repo, branch string) (*github.Protection, error)
 	GetCollaborators(ctx *types.Context, owner, repo string) ([]*github.User, error)
+	GetDirectCollaborators(ctx *types.Context, owner, repo string) ([]*github.User, error)
 	GetHooks(ctx *types.Context, owner, repo string) ([]*github.Hook, error)
+	// GetHookDeliveries returns recent deliveries of the hook ordered by newest first. It returns nil if deliveries are not accessible.
+	GetHookDeliveries(ctx *types.Context, owner, repo string, hookID int64) ([]*github.HookDelivery, error)
 	GetTeams(ctx *types.Context, owner, repo string) ([]*github.Team, error)
+	GetEnvironments(ctx *types.Context, owner, repo string) ([]*github.Environment, error)
+	GetDeploymentBranchPolicies(ctx *types.Context, owner, repo, env string) ([]*model.DeploymentBranchPolicy, error)
+	GetDeploymentProtectionRules(ctx *types.Context, owner, repo, env string) ([]*model.DeploymentProtectionRule, error)
+	// GetSecurityAndAnalysis returns nil if the repository is not accessible
+	GetSecurityAndAnalysis(ctx *types.Context, owner, repo string) (*model.SecurityAndAnalysis, error)
+	// GetRepoRunners returns nil if self-hosted runners of the repository are not accessible
+	GetRepoRunners(ctx *types.Context, owner, repo string) ([]*github.Runner, error)
+	// GetCommits returns up to limit commits of ref ordered by newest first. It returns nil if the repository is empty.
+	GetCommits(ctx *types.Context, owner, repo, ref string, limit int) ([]*github.RepositoryCommit, error)
+	// GetOpenPullRequests returns nil if pull requests of the repository are not accessible
+	GetOpenPullRequests(ctx *types.Context, owner, repo string) ([]*github.PullRequest, error)
+	// GetLatestRelease returns nil if the repository has no release
+	GetLatestRelease(ctx *types.Context, owner, repo string) (*github.RepositoryRelease, error)
+	// GetReleases returns up to limit releases ordered by newest first. It returns nil if releases are not accessible.
+	GetReleases(ctx *types.Context, owner, repo string, limit int) ([]*model.Release, error)
+	// GetTagProtections returns nil if legacy tag protection API is not available
+	GetTagProtections(ctx *types.Context, owner, repo string) ([]*model.TagProtection, error)
+	// GetRepoRulesets returns rulesets for target ("branch" or "tag") including ones of organization. It returns nil if rulesets are not accessible.
+	GetRepoRulesets(ctx *types.Context, owner, repo, target string) ([]*model.Ruleset, error)
+
+	// GetSBOM returns SBOM exported from dependency graph. It returns nil if dependency graph is not enabled or not accessible.
+	GetSBOM(ctx *types.Context, owner, repo string) (*model.SBOM, error)
+
+	// GetFile returns a file in default branch. It returns nil if the file does not exist.
+	GetFile(ctx *types.Context, owner, repo, path string) (*github.RepositoryContent, error)
+
+	// Alert methods return nil if the alert feature is not enabled or not accessible, and an empty slice if there is no open alert.
+	GetDependabotAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error)
+	GetCodeScanningAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error)
+	GetSecretScanningAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error)
 }
 
 type client struct {
 	client *github.Client
 }
 
-func New(appID, installID int64, privateKey []byte) (Client, error) {
-	itr, err := ghinstallation.New(http.DefaultTransport, appID, installID, privateKey)
+type Option func(cfg *config)
+
+type config struct {
+	transportOptions []TransportOption
+	cacheDir         string
+	cacheTTL         time.Duration
+	baseURL          string
+}
+
+// WithTransport sets options of RateLimitTransport.
+func WithTransport(options ...TransportOption) Option {
+	return func(cfg *config) {
+		cfg.transportOptions = append(cfg.transportOptions, options...)
+	}
+}
+
+// WithCache enables on-disk cache of responses in dir. See CacheTransport about ttl.
+func WithCache(dir string, ttl time.Duration) Option {
+	return func(cfg *config) {
+		cfg.cacheDir = dir
+		cfg.cacheTTL = ttl
+	}
+}
+
+// WithBaseURL replaces URL of GitHub API (e.g. "https://api.github.com"). It is mainly for testing.
+func WithBaseURL(baseURL string) Option {
+	return func(cfg *config) {
+		cfg.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// newGitHubClient creates API client authenticated as the installation. Requests are sent through CacheTransport (if enabled) and RateLimitTransport.
+func newGitHubClient(appID, installID int64, privateKey []byte, options []Option) (*github.Client, error) {
+	var cfg config
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	var tr http.RoundTripper = NewRateLimitTransport(http.DefaultTransport, cfg.transportOptions...)
+	if cfg.cacheDir != "" {
+		cache, err := NewCacheTransport(tr, cfg.cacheDir, fmt.Sprintf("%d/%d", appID, installID), cfg.cacheTTL)
+		if err != nil {
+			return nil, err
+		}
+		tr = cache
+	}
+
+	itr, err := ghinstallation.New(tr, appID, installID, privateKey)
 	if err != nil {
 		return nil, goerr.Wrap(err)
 	}
 
+	ghClient := github.NewClient(&http.Client{Transport: itr})
+	if cfg.baseURL != "" {
+		itr.BaseURL = cfg.baseURL
+		baseURL, err := url.Parse(cfg.baseURL + "/")
+		if err != nil {
+			return nil, goerr.Wrap(err).With("url", cfg.baseURL)
+		}
+		ghClient.BaseURL = baseURL
+	}
+	return ghClient, nil
+}
+
+// New creates a client of REST API.
+func New(appID, installID int64, privateKey []byte, options ...Option) (Client, error) {
+	ghClient, err := newGitHubClient(appID, installID, privateKey, options)
+	if err != nil {
+		return nil, err
+	}
+
 	return &client{
-		client: github.NewClient(&http.Client{Transport: itr}),
+		client: ghClient,
 	}, nil
 }
 
@@ -112,11 +248,20 @@ func (x *client) GetBranchProtection(ctx *types.Context, owner, repo, branch str
 }
 
 func (x *client) GetCollaborators(ctx *types.Context, owner, repo string) ([]*github.User, error) {
+	return x.listCollaborators(ctx, owner, repo, "all")
+}
+
+func (x *client) GetDirectCollaborators(ctx *types.Context, owner, repo string) ([]*github.User, error) {
+	return x.listCollaborators(ctx, owner, repo, "direct")
+}
+
+func (x *client) listCollaborators(ctx *types.Context, owner, repo, affiliation string) ([]*github.User, error) {
 	const perPage = 100
 	var users []*github.User
 
 	for page := 1; ; page++ {
 		got, resp, err := x.client.Repositories.ListCollaborators(ctx, owner, repo, &github.ListCollaboratorsOptions{
+			Affiliation: affiliation,
 			ListOptions: github.ListOptions{
 				Page:    page,
 				PerPage: perPage,
@@ -193,3 +338,1095 @@ func (x *client) GetTeams(ctx *types.Context, owner, repo string) ([]*github.Tea
 
 	return teams, nil
 }
+
+// isUnavailable returns true if the request failed because the resource does not exist or the installation is not permitted to access it. Rate limit errors are not regarded as unavailable.
+func isUnavailable(resp *github.Response, err error) bool {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return false
+	}
+
+	return resp != nil &&
+		(resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden)
+}
+
+// GetEnvironments retrieves all environments of the repository. ListEnvironments of go-github v42 does not support pagination and returns only the first page.
+func (x *client) GetEnvironments(ctx *types.Context, owner, repo string) ([]*github.Environment, error) {
+	const perPage = 100
+	var envs []*github.Environment
+
+	for page := 1; ; page++ {
+		req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/environments?per_page=%d&page=%d", owner, repo, perPage, page), nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got github.EnvResponse
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("repo", repo).With("code", resp.StatusCode).Debug("environments are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		envs = append(envs, got.Environments...)
+		if len(got.Environments) == 0 || got.GetTotalCount() <= len(envs) {
+			break
+		}
+	}
+
+	return envs, nil
+}
+
+func (x *client) GetDeploymentBranchPolicies(ctx *types.Context, owner, repo, env string) ([]*model.DeploymentBranchPolicy, error) {
+	const perPage = 100
+	var policies []*model.DeploymentBranchPolicy
+
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("repos/%s/%s/environments/%s/deployment-branch-policies?per_page=%d&page=%d",
+			owner, repo, url.PathEscape(env), perPage, page)
+		req, err := x.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got struct {
+			BranchPolicies []*model.DeploymentBranchPolicy `json:"branch_policies"`
+		}
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err).With("env", env)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("env", env)
+		}
+
+		policies = append(policies, got.BranchPolicies...)
+		if len(got.BranchPolicies) < perPage {
+			break
+		}
+	}
+
+	return policies, nil
+}
+
+func (x *client) GetDeploymentProtectionRules(ctx *types.Context, owner, repo, env string) ([]*model.DeploymentProtectionRule, error) {
+	u := fmt.Sprintf("repos/%s/%s/environments/%s/deployment_protection_rules", owner, repo, url.PathEscape(env))
+	req, err := x.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var got struct {
+		Rules []*model.DeploymentProtectionRule `json:"custom_deployment_protection_rules"`
+	}
+	resp, err := x.client.Do(ctx, req, &got)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("env", env)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("env", env)
+	}
+
+	return got.Rules, nil
+}
+
+// GetSecurityAndAnalysis retrieves the repository again because Repository of go-github v42 does not have all fields of security_and_analysis.
+func (x *client) GetSecurityAndAnalysis(ctx *types.Context, owner, repo string) (*model.SecurityAndAnalysis, error) {
+	req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s", owner, repo), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var got struct {
+		SecurityAndAnalysis *model.SecurityAndAnalysis `json:"security_and_analysis"`
+	}
+	resp, err := x.client.Do(ctx, req, &got)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			utils.Logger.With("repo", repo).With("code", resp.StatusCode).Debug("security and analysis settings are not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+	}
+
+	return got.SecurityAndAnalysis, nil
+}
+
+func (x *client) GetDependabotAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error) {
+	const perPage = 100
+	alerts := []*model.SecurityAlert{}
+
+	// Dependabot alerts API supports only cursor based pagination
+	var after string
+	for {
+		u := fmt.Sprintf("repos/%s/%s/dependabot/alerts?state=open&per_page=%d", owner, repo, perPage)
+		if after != "" {
+			u += "&after=" + url.QueryEscape(after)
+		}
+		req, err := x.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got []*struct {
+			Number     int    `json:"number"`
+			HTMLURL    string `json:"html_url"`
+			Dependency struct {
+				Package struct {
+					Ecosystem string `json:"ecosystem"`
+					Name      string `json:"name"`
+				} `json:"package"`
+			} `json:"dependency"`
+			SecurityAdvisory struct {
+				Severity string `json:"severity"`
+			} `json:"security_advisory"`
+			CreatedAt time.Time `json:"created_at"`
+		}
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		for _, alert := range got {
+			alerts = append(alerts, &model.SecurityAlert{
+				Number:    alert.Number,
+				Severity:  alert.SecurityAdvisory.Severity,
+				Summary:   alert.Dependency.Package.Ecosystem + ":" + alert.Dependency.Package.Name,
+				HTMLURL:   alert.HTMLURL,
+				CreatedAt: alert.CreatedAt,
+			})
+		}
+
+		if resp.After == "" || len(got) < perPage {
+			break
+		}
+		after = resp.After
+	}
+
+	return alerts, nil
+}
+
+func (x *client) GetCodeScanningAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error) {
+	const perPage = 100
+	alerts := []*model.SecurityAlert{}
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.CodeScanning.ListAlertsForRepo(ctx, owner, repo, &github.AlertListOptions{
+			State: "open",
+			ListOptions: github.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			},
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		for _, alert := range got {
+			severity := alert.GetRule().GetSecuritySeverityLevel()
+			if severity == "" {
+				severity = alert.GetRule().GetSeverity()
+			}
+			alerts = append(alerts, &model.SecurityAlert{
+				Number:    parseAlertNumber(alert.GetHTMLURL()),
+				Severity:  severity,
+				Summary:   alert.GetRule().GetID(),
+				HTMLURL:   alert.GetHTMLURL(),
+				CreatedAt: alert.GetCreatedAt().Time,
+			})
+		}
+
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return alerts, nil
+}
+
+// parseAlertNumber extracts alert number from HTML URL because go-github v42 does not support `number` field of code scanning alert.
+func parseAlertNumber(htmlURL string) int {
+	idx := strings.LastIndex(htmlURL, "/")
+	if idx < 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(htmlURL[idx+1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func (x *client) GetSecretScanningAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error) {
+	const perPage = 100
+	alerts := []*model.SecurityAlert{}
+
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("repos/%s/%s/secret-scanning/alerts?state=open&per_page=%d&page=%d", owner, repo, perPage, page)
+		req, err := x.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got []*struct {
+			Number     int       `json:"number"`
+			HTMLURL    string    `json:"html_url"`
+			SecretType string    `json:"secret_type"`
+			CreatedAt  time.Time `json:"created_at"`
+		}
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		for _, alert := range got {
+			alerts = append(alerts, &model.SecurityAlert{
+				Number:    alert.Number,
+				Summary:   alert.SecretType,
+				HTMLURL:   alert.HTMLURL,
+				CreatedAt: alert.CreatedAt,
+			})
+		}
+
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return alerts, nil
+}
+
+func (x *client) GetFile(ctx *types.Context, owner, repo, path string) (*github.RepositoryContent, error) {
+	file, _, resp, err := x.client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("path", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("body", body)
+	}
+
+	// file is nil if path is a directory
+	return file, nil
+}
+
+func (x *client) GetOrganization(ctx *types.Context, org string) (*github.Organization, error) {
+	got, resp, err := x.client.Organizations.Get(ctx, org)
+	if err != nil {
+		// owner is a user account or the installation is not permitted to read the organization
+		if isUnavailable(resp, err) {
+			utils.Logger.With("org", org).With("code", resp.StatusCode).Debug("organization is not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("org", org)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("body", body)
+	}
+
+	return got, nil
+}
+
+func (x *client) GetOrgHooks(ctx *types.Context, org string) ([]*github.Hook, error) {
+	const perPage = 100
+	var hooks []*github.Hook
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.Organizations.ListHooks(ctx, org, &github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("org", org).With("code", resp.StatusCode).Debug("organization webhooks are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		hooks = append(hooks, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return hooks, nil
+}
+
+func (x *client) GetOrgActionsPermissions(ctx *types.Context, org string) (*github.ActionsPermissions, error) {
+	got, resp, err := x.client.Organizations.GetActionsPermissions(ctx, org)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("body", body)
+	}
+
+	return got, nil
+}
+
+func (x *client) GetOrgActionsAllowed(ctx *types.Context, org string) (*github.ActionsAllowed, error) {
+	got, resp, err := x.client.Organizations.GetActionsAllowed(ctx, org)
+	if err != nil {
+		// selected-actions is available only if allowed_actions is "selected"
+		if isUnavailable(resp, err) || (resp != nil && resp.StatusCode == http.StatusConflict) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("body", body)
+	}
+
+	return got, nil
+}
+
+// GetOrgSAMLSSOEnabled checks SAML SSO by credential authorizations API that is available only for organization using SAML SSO. The API responds 404 not only for organization without SAML SSO but also for organization not on GitHub Enterprise Cloud and caller without owner permission, so SAML SSO is regarded as enabled only if the API is available.
+func (x *client) GetOrgSAMLSSOEnabled(ctx *types.Context, org string) (*bool, error) {
+	req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/credential-authorizations?per_page=1", org), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var got []interface{}
+	resp, err := x.client.Do(ctx, req, &got)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			utils.Logger.With("org", org).With("code", resp.StatusCode).Debug("SAML SSO status can not be determined")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+
+	return github.Bool(true), nil
+}
+
+func (x *client) listOrgMembers(ctx *types.Context, org string, opt github.ListMembersOptions) ([]*github.User, *github.Response, error) {
+	const perPage = 100
+	var users []*github.User
+
+	for page := 1; ; page++ {
+		opt.ListOptions = github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		}
+		got, resp, err := x.client.Organizations.ListMembers(ctx, org, &opt)
+		if err != nil {
+			return nil, resp, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, resp, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		users = append(users, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return users, nil, nil
+}
+
+func (x *client) GetOrgMembers(ctx *types.Context, org, role string) ([]*github.User, error) {
+	users, resp, err := x.listOrgMembers(ctx, org, github.ListMembersOptions{Role: role})
+	if err != nil {
+		if isUnavailable(resp, err) {
+			utils.Logger.With("org", org).With("code", resp.StatusCode).Debug("members are not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("role", role)
+	}
+	return users, nil
+}
+
+func (x *client) GetOrgMembersWithout2FA(ctx *types.Context, org string) ([]*github.User, error) {
+	users, resp, err := x.listOrgMembers(ctx, org, github.ListMembersOptions{Filter: "2fa_disabled"})
+	if err != nil {
+		// 2fa_disabled filter is allowed only for organization owner
+		if isUnavailable(resp, err) || (resp != nil && resp.StatusCode == http.StatusUnprocessableEntity) {
+			utils.Logger.With("org", org).Debug("2FA status of members is not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if users == nil {
+		users = []*github.User{}
+	}
+	return users, nil
+}
+
+func (x *client) GetOutsideCollaborators(ctx *types.Context, org string) ([]*github.User, error) {
+	const perPage = 100
+	var users []*github.User
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.Organizations.ListOutsideCollaborators(ctx, org, &github.ListOutsideCollaboratorsOptions{
+			ListOptions: github.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			},
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		users = append(users, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return users, nil
+}
+
+func (x *client) GetOrgInvitations(ctx *types.Context, org string) ([]*github.Invitation, error) {
+	const perPage = 100
+	var invitations []*github.Invitation
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.Organizations.ListPendingOrgInvitations(ctx, org, &github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		invitations = append(invitations, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return invitations, nil
+}
+
+func (x *client) GetOrgTeams(ctx *types.Context, org string) ([]*github.Team, error) {
+	const perPage = 100
+	var teams []*github.Team
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.Teams.ListTeams(ctx, org, &github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		teams = append(teams, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return teams, nil
+}
+
+func (x *client) GetTeamMembers(ctx *types.Context, org, slug, role string) ([]*github.User, error) {
+	const perPage = 100
+	var users []*github.User
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.Teams.ListTeamMembersBySlug(ctx, org, slug, &github.TeamListTeamMembersOptions{
+			Role: role,
+			ListOptions: github.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			},
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("team", slug).With("code", resp.StatusCode).Debug("team members are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err).With("team", slug)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		users = append(users, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return users, nil
+}
+
+func (x *client) GetOrgInstallations(ctx *types.Context, org string) ([]*model.AppInstallation, error) {
+	const perPage = 100
+	var installations []*model.AppInstallation
+
+	for page := 1; ; page++ {
+		req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/installations?per_page=%d&page=%d", org, perPage, page), nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got struct {
+			Installations []*model.AppInstallation `json:"installations"`
+		}
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("org", org).Debug("app installations are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		installations = append(installations, got.Installations...)
+		if len(got.Installations) < perPage {
+			break
+		}
+	}
+
+	return installations, nil
+}
+
+// listRunners retrieves runners by list function of go-github. It returns nil if runners are not accessible.
+func listRunners(list func(opts *github.ListOptions) (*github.Runners, *github.Response, error)) ([]*github.Runner, error) {
+	const perPage = 100
+	runners := []*github.Runner{}
+
+	for page := 1; ; page++ {
+		got, resp, err := list(&github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		runners = append(runners, got.Runners...)
+		if len(got.Runners) < perPage {
+			break
+		}
+	}
+
+	return runners, nil
+}
+
+func (x *client) GetOrgRunners(ctx *types.Context, org string) ([]*github.Runner, error) {
+	runners, err := listRunners(func(opts *github.ListOptions) (*github.Runners, *github.Response, error) {
+		return x.client.Actions.ListOrganizationRunners(ctx, org, opts)
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err).With("org", org)
+	}
+	if runners == nil {
+		utils.Logger.With("org", org).Debug("self-hosted runners of organization are not available")
+	}
+	return runners, nil
+}
+
+func (x *client) GetRunnerGroupRunners(ctx *types.Context, org string, groupID int64) ([]*github.Runner, error) {
+	runners, err := listRunners(func(opts *github.ListOptions) (*github.Runners, *github.Response, error) {
+		return x.client.Actions.ListRunnerGroupRunners(ctx, org, groupID, opts)
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err).With("org", org).With("group", groupID)
+	}
+	return runners, nil
+}
+
+func (x *client) GetRepoRunners(ctx *types.Context, owner, repo string) ([]*github.Runner, error) {
+	runners, err := listRunners(func(opts *github.ListOptions) (*github.Runners, *github.Response, error) {
+		return x.client.Actions.ListRunners(ctx, owner, repo, opts)
+	})
+	if err != nil {
+		return nil, goerr.Wrap(err).With("repo", repo)
+	}
+	return runners, nil
+}
+
+func (x *client) GetOrgRunnerGroups(ctx *types.Context, org string) ([]*model.RunnerGroup, error) {
+	const perPage = 100
+	groups := []*model.RunnerGroup{}
+
+	for page := 1; ; page++ {
+		req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/actions/runner-groups?per_page=%d&page=%d", org, perPage, page), nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got struct {
+			RunnerGroups []*model.RunnerGroup `json:"runner_groups"`
+		}
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("org", org).Debug("runner groups are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		groups = append(groups, got.RunnerGroups...)
+		if len(got.RunnerGroups) < perPage {
+			break
+		}
+	}
+
+	return groups, nil
+}
+
+func (x *client) GetRunnerGroupRepos(ctx *types.Context, org string, groupID int64) ([]*github.Repository, error) {
+	const perPage = 100
+	var repos []*github.Repository
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.Actions.ListRepositoryAccessRunnerGroup(ctx, org, groupID, &github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err).With("group", groupID)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		repos = append(repos, got.Repositories...)
+		if len(got.Repositories) < perPage {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+func (x *client) GetCommits(ctx *types.Context, owner, repo, ref string, limit int) ([]*github.RepositoryCommit, error) {
+	perPage := 100
+	if limit < perPage {
+		perPage = limit
+	}
+	var commits []*github.RepositoryCommit
+
+	for page := 1; len(commits) < limit; page++ {
+		got, resp, err := x.client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{
+			SHA: ref,
+			ListOptions: github.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			},
+		})
+		if err != nil {
+			// commits API responds 409 for empty repository
+			if isUnavailable(resp, err) || (resp != nil && resp.StatusCode == http.StatusConflict) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err).With("ref", ref)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		commits = append(commits, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, nil
+}
+
+func (x *client) GetOpenPullRequests(ctx *types.Context, owner, repo string) ([]*github.PullRequest, error) {
+	const perPage = 100
+	var pulls []*github.PullRequest
+
+	for page := 1; ; page++ {
+		got, resp, err := x.client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{
+			State: "open",
+			ListOptions: github.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			},
+		})
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("repo", repo).With("code", resp.StatusCode).Debug("pull requests are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, types.ErrUnexpectedGitHubResp.New().
+				With("code", resp.StatusCode).With("body", body)
+		}
+
+		pulls = append(pulls, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return pulls, nil
+}
+
+func (x *client) GetLatestRelease(ctx *types.Context, owner, repo string) (*github.RepositoryRelease, error) {
+	got, resp, err := x.client.Repositories.GetLatestRelease(ctx, owner, repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("body", body)
+	}
+
+	return got, nil
+}
+
+// hookDeliveryLimit is number of recent deliveries to be retrieved per webhook
+const hookDeliveryLimit = 100
+
+// checkHookDeliveries converts response of hook deliveries API. It returns nil if deliveries are not accessible.
+func checkHookDeliveries(got []*github.HookDelivery, resp *github.Response, err error) ([]*github.HookDelivery, error) {
+	if err != nil {
+		if isUnavailable(resp, err) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, types.ErrUnexpectedGitHubResp.New().
+			With("code", resp.StatusCode).With("body", body)
+	}
+
+	if got == nil {
+		got = []*github.HookDelivery{}
+	}
+	return got, nil
+}
+
+func (x *client) GetHookDeliveries(ctx *types.Context, owner, repo string, hookID int64) ([]*github.HookDelivery, error) {
+	deliveries, err := checkHookDeliveries(x.client.Repositories.ListHookDeliveries(ctx, owner, repo, hookID, &github.ListCursorOptions{
+		PerPage: hookDeliveryLimit,
+	}))
+	if err != nil {
+		return nil, goerr.Wrap(err).With("repo", repo).With("hook", hookID)
+	}
+	return deliveries, nil
+}
+
+func (x *client) GetOrgHookDeliveries(ctx *types.Context, org string, hookID int64) ([]*github.HookDelivery, error) {
+	deliveries, err := checkHookDeliveries(x.client.Organizations.ListHookDeliveries(ctx, org, hookID, &github.ListCursorOptions{
+		PerPage: hookDeliveryLimit,
+	}))
+	if err != nil {
+		return nil, goerr.Wrap(err).With("org", org).With("hook", hookID)
+	}
+	return deliveries, nil
+}
+
+func (x *client) GetReleases(ctx *types.Context, owner, repo string, limit int) ([]*model.Release, error) {
+	perPage := 100
+	if limit < perPage {
+		perPage = limit
+	}
+	var releases []*model.Release
+
+	for page := 1; len(releases) < limit; page++ {
+		req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/releases?per_page=%d&page=%d", owner, repo, perPage, page), nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got []*model.Release
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				utils.Logger.With("repo", repo).With("code", resp.StatusCode).Debug("releases are not available")
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		releases = append(releases, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	if len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases, nil
+}
+
+func (x *client) GetTagProtections(ctx *types.Context, owner, repo string) ([]*model.TagProtection, error) {
+	req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/tags/protection", owner, repo), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	got := []*model.TagProtection{}
+	resp, err := x.client.Do(ctx, req, &got)
+	if err != nil {
+		// the API responds 410 after it was sunset
+		if isUnavailable(resp, err) || (resp != nil && resp.StatusCode == http.StatusGone) {
+			utils.Logger.With("repo", repo).Debug("tag protection API is not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+	}
+
+	return got, nil
+}
+
+func (x *client) GetRepoRulesets(ctx *types.Context, owner, repo, target string) ([]*model.Ruleset, error) {
+	const perPage = 100
+	var summaries []*model.Ruleset
+
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("repos/%s/%s/rulesets?includes_parents=true&targets=%s&per_page=%d&page=%d",
+			owner, repo, url.QueryEscape(target), perPage, page)
+		req, err := x.client.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got []*model.Ruleset
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		summaries = append(summaries, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	// conditions and rules are available only by get API
+	rulesets := []*model.Ruleset{}
+	for _, summary := range summaries {
+		if summary.Target != "" && summary.Target != target {
+			continue
+		}
+
+		req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/rulesets/%d", owner, repo, summary.ID), nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		var ruleset model.Ruleset
+		resp, err := x.client.Do(ctx, req, &ruleset)
+		if err != nil {
+			return nil, goerr.Wrap(err).With("ruleset", summary.ID)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+		rulesets = append(rulesets, &ruleset)
+	}
+
+	return rulesets, nil
+}
+
+func (x *client) GetOrgPropertySchema(ctx *types.Context, org string) ([]*model.CustomProperty, error) {
+	req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/properties/schema", org), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	got := []*model.CustomProperty{}
+	resp, err := x.client.Do(ctx, req, &got)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			utils.Logger.With("org", org).Debug("custom properties are not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+	}
+
+	return got, nil
+}
+
+func (x *client) GetOrgPropertyValues(ctx *types.Context, org string) ([]*model.RepoCustomPropertyValues, error) {
+	const perPage = 100
+	values := []*model.RepoCustomPropertyValues{}
+
+	for page := 1; ; page++ {
+		req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/properties/values?per_page=%d&page=%d", org, perPage, page), nil)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		var got []*model.RepoCustomPropertyValues
+		resp, err := x.client.Do(ctx, req, &got)
+		if err != nil {
+			if isUnavailable(resp, err) {
+				return nil, nil
+			}
+			return nil, goerr.Wrap(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+		}
+
+		values = append(values, got...)
+		if len(got) < perPage {
+			break
+		}
+	}
+
+	return values, nil
+}
+
+func (x *client) GetSBOM(ctx *types.Context, owner, repo string) (*model.SBOM, error) {
+	req, err := x.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/dependency-graph/sbom", owner, repo), nil)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var got struct {
+		SBOM *model.SBOM `json:"sbom"`
+	}
+	resp, err := x.client.Do(ctx, req, &got)
+	if err != nil {
+		if isUnavailable(resp, err) {
+			utils.Logger.With("repo", repo).Debug("dependency graph is not available")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+	}
+
+	return got.SBOM, nil
+}
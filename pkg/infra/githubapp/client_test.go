@@ -1,11 +1,22 @@
 package githubapp_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
 	"github.com/m-mizutani/ghaudit/pkg/domain/types"
 	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
 	"github.com/stretchr/testify/assert"
@@ -38,3 +49,245 @@ func TestGitHubClient(t *testing.T) {
 	repoNames := []string{repos[0].GetName(), repos[1].GetName()}
 	assert.Contains(t, repoNames, "test-repo")
 }
+
+func TestGraphQLClient(t *testing.T) {
+	envAppID := os.Getenv(types.EnvAppID)
+	envInstallID := os.Getenv(types.EnvInstallID)
+	envKeyFile := os.Getenv(types.EnvPrivateKeyFile)
+
+	if envAppID == "" || envInstallID == "" || envKeyFile == "" {
+		t.Skip("environment variables required")
+	}
+
+	appID, err := strconv.ParseInt(envAppID, 10, 64)
+	require.NoError(t, err)
+	installID, err := strconv.ParseInt(envInstallID, 10, 64)
+	require.NoError(t, err)
+	keyFile := filepath.Join("..", "..", "..", filepath.Clean(envKeyFile))
+	keyData, err := os.ReadFile(keyFile)
+	require.NoError(t, err)
+
+	restClient, err := githubapp.New(appID, installID, keyData)
+	require.NoError(t, err)
+	gqlClient, err := githubapp.NewGraphQL(appID, installID, keyData)
+	require.NoError(t, err)
+
+	ctx := types.NewContext()
+	repos, err := gqlClient.GetRepos(ctx, "mizutani-sandbox")
+	require.NoError(t, err)
+
+	// GraphQL client should respond same data with REST client
+	for _, repo := range repos {
+		owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+
+		expectedBranches, err := restClient.GetBranches(ctx, owner, name)
+		require.NoError(t, err)
+		branches, err := gqlClient.GetBranches(ctx, owner, name)
+		require.NoError(t, err)
+		require.Len(t, branches, len(expectedBranches))
+
+		expectedUsers, err := restClient.GetCollaborators(ctx, owner, name)
+		require.NoError(t, err)
+		users, err := gqlClient.GetCollaborators(ctx, owner, name)
+		require.NoError(t, err)
+		require.Len(t, users, len(expectedUsers))
+		var logins []string
+		for _, user := range users {
+			logins = append(logins, user.GetLogin())
+		}
+		for _, user := range expectedUsers {
+			assert.Contains(t, logins, user.GetLogin())
+		}
+	}
+}
+
+// newTestClient returns REST and GraphQL clients sending requests to a test server handled by mux. The access token endpoint is provided by the server.
+func newTestClient(t *testing.T, mux *http.ServeMux) (githubapp.Client, githubapp.Client) {
+	mux.HandleFunc("/app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":"test-token","expires_at":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyData := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	rest, err := githubapp.New(1, 1, keyData, githubapp.WithBaseURL(server.URL))
+	require.NoError(t, err)
+	gql, err := githubapp.NewGraphQL(1, 1, keyData, githubapp.WithBaseURL(server.URL))
+	require.NoError(t, err)
+	return rest, gql
+}
+
+func TestUnavailableOrgMembers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/my-org/members", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	})
+	client, _ := newTestClient(t, mux)
+
+	users, err := client.GetOrgMembers(types.NewContext(), "my-org", "admin")
+	require.NoError(t, err)
+	assert.Nil(t, users)
+}
+
+func TestEnvironments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/blue/environments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+		// 101 environments over 2 pages
+		var envs []string
+		switch r.URL.Query().Get("page") {
+		case "1":
+			for i := 0; i < 100; i++ {
+				envs = append(envs, fmt.Sprintf(`{"id": %d, "name": "env%d"}`, i, i))
+			}
+		case "2":
+			envs = append(envs, `{"id": 100, "name": "production", "deployment_branch_policy": {"protected_branches": false, "custom_branch_policies": true}}`)
+		default:
+			t.Errorf("unexpected page: %s", r.URL.Query().Get("page"))
+		}
+		_, _ = w.Write([]byte(`{"total_count": 101, "environments": [` + strings.Join(envs, ",") + `]}`))
+	})
+	mux.HandleFunc("/repos/my-org/blue/environments/production/deployment-branch-policies", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"total_count": 1, "branch_policies": [{"id": 1, "node_id": "P_1", "name": "release/*", "type": "branch"}]}`))
+	})
+	mux.HandleFunc("/repos/my-org/blue/environments/production/deployment_protection_rules", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"total_count": 1, "custom_deployment_protection_rules": [
+  {"id": 3, "node_id": "R_3", "enabled": true, "app": {"id": 7, "slug": "deploy-gate", "integration_url": "https://api.github.com/apps/deploy-gate", "node_id": "A_7"}}
+]}`))
+	})
+	mux.HandleFunc("/repos/my-org/red/environments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	client, _ := newTestClient(t, mux)
+	ctx := types.NewContext()
+
+	envs, err := client.GetEnvironments(ctx, "my-org", "blue")
+	require.NoError(t, err)
+	require.Len(t, envs, 101)
+	assert.Equal(t, "production", envs[100].GetName())
+	assert.True(t, envs[100].GetDeploymentBranchPolicy().GetCustomBranchPolicies())
+
+	policies, err := client.GetDeploymentBranchPolicies(ctx, "my-org", "blue", "production")
+	require.NoError(t, err)
+	assert.Equal(t, []*model.DeploymentBranchPolicy{
+		{ID: 1, NodeID: "P_1", Name: "release/*", Type: "branch"},
+	}, policies)
+
+	rules, err := client.GetDeploymentProtectionRules(ctx, "my-org", "blue", "production")
+	require.NoError(t, err)
+	assert.Equal(t, []*model.DeploymentProtectionRule{
+		{ID: 3, NodeID: "R_3", Enabled: true, App: &model.DeploymentProtectionRuleApp{
+			ID: 7, Slug: "deploy-gate", IntegrationURL: "https://api.github.com/apps/deploy-gate", NodeID: "A_7",
+		}},
+	}, rules)
+
+	envs, err = client.GetEnvironments(ctx, "my-org", "red")
+	require.NoError(t, err)
+	assert.Nil(t, envs)
+}
+
+func TestSecurityAlerts(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/blue/dependabot/alerts", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		assert.Empty(t, r.URL.Query().Get("page"))
+
+		// 101 alerts over 2 pages by cursor
+		var alerts []string
+		switch r.URL.Query().Get("after") {
+		case "":
+			for i := 1; i <= 100; i++ {
+				alerts = append(alerts, fmt.Sprintf(`{"number": %d, "dependency": {"package": {"ecosystem": "npm", "name": "pkg%d"}}, "security_advisory": {"severity": "low"}}`, i, i))
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/my-org/blue/dependabot/alerts?state=open&per_page=100&after=cursor1>; rel="next"`, r.Host))
+		case "cursor1":
+			alerts = append(alerts, `{"number": 101, "html_url": "https://github.com/my-org/blue/security/dependabot/101", "dependency": {"package": {"ecosystem": "go", "name": "example.com/mod"}}, "security_advisory": {"severity": "critical"}, "created_at": "2022-03-01T00:00:00Z"}`)
+		default:
+			t.Errorf("unexpected cursor: %s", r.URL.Query().Get("after"))
+		}
+		_, _ = w.Write([]byte(`[` + strings.Join(alerts, ",") + `]`))
+	})
+	mux.HandleFunc("/repos/my-org/blue/secret-scanning/alerts", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/repos/my-org/blue/code-scanning/alerts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Advanced Security must be enabled for this repository to use code scanning."}`))
+	})
+	mux.HandleFunc("/repos/my-org/blue", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "blue", "security_and_analysis": {"secret_scanning": {"status": "enabled"}, "secret_scanning_push_protection": {"status": "disabled"}}}`))
+	})
+	mux.HandleFunc("/repos/my-org/red", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	client, _ := newTestClient(t, mux)
+	ctx := types.NewContext()
+
+	t.Run("Dependabot alerts are retrieved by cursor", func(t *testing.T) {
+		alerts, err := client.GetDependabotAlerts(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		require.Len(t, alerts, 101)
+		assert.Equal(t, &model.SecurityAlert{
+			Number:    101,
+			Severity:  "critical",
+			Summary:   "go:example.com/mod",
+			HTMLURL:   "https://github.com/my-org/blue/security/dependabot/101",
+			CreatedAt: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+		}, alerts[100])
+	})
+
+	t.Run("no open alert is empty", func(t *testing.T) {
+		alerts, err := client.GetSecretScanningAlerts(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.NotNil(t, alerts)
+		assert.Empty(t, alerts)
+	})
+
+	t.Run("unavailable feature is nil", func(t *testing.T) {
+		alerts, err := client.GetCodeScanningAlerts(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.Nil(t, alerts)
+	})
+
+	t.Run("security and analysis settings", func(t *testing.T) {
+		analysis, err := client.GetSecurityAndAnalysis(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.Equal(t, &model.SecurityAndAnalysis{
+			SecretScanning:               &model.SecurityAndAnalysisStatus{Status: "enabled"},
+			SecretScanningPushProtection: &model.SecurityAndAnalysisStatus{Status: "disabled"},
+		}, analysis)
+
+		analysis, err = client.GetSecurityAndAnalysis(ctx, "my-org", "red")
+		require.NoError(t, err)
+		assert.Nil(t, analysis)
+	})
+}
+
+func TestOrgSAMLSSOEnabled(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/sso-org/credential-authorizations", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	// 404 is returned for organization without SAML SSO, organization not on GitHub Enterprise Cloud and caller without owner permission
+	mux.HandleFunc("/orgs/my-org/credential-authorizations", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	client, _ := newTestClient(t, mux)
+	ctx := types.NewContext()
+
+	enabled, err := client.GetOrgSAMLSSOEnabled(ctx, "sso-org")
+	require.NoError(t, err)
+	assert.Equal(t, github.Bool(true), enabled)
+
+	enabled, err = client.GetOrgSAMLSSOEnabled(ctx, "my-org")
+	require.NoError(t, err)
+	assert.Nil(t, enabled)
+}
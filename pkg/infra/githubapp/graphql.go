@@ -0,0 +1,585 @@
+package githubapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/goerr"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+)
+
+// graphqlBatchSize is number of repositories retrieved by one GraphQL query
+const graphqlBatchSize = 20
+
+// graphqlClient retrieves branches with protection rules and collaborators of many repositories by one GraphQL query, and teams of all repositories by organization teams query. Repositories listed by GetRepos are split into batches and a batch is queried when data of one of the repositories is required first. Other data, and data that can not be retrieved completely by GraphQL (e.g. more than 100 branches, push restrictions), are retrieved by REST API.
+type graphqlClient struct {
+	*client
+
+	mutex   sync.Mutex
+	batches map[string]*graphqlBatch
+
+	teamsOnce sync.Once
+	teams     map[string][]*github.Team
+	teamsErr  error
+}
+
+type graphqlBatch struct {
+	once  sync.Once
+	ids   []string
+	repos map[string]*graphqlRepoData
+	err   error
+}
+
+// graphqlRepoData is converted data of a repository. nil fields are retrieved by REST API.
+type graphqlRepoData struct {
+	branches            []*github.Branch
+	protections         map[string]*github.Protection
+	collaborators       []*github.User
+	directCollaborators []*github.User
+}
+
+// NewGraphQL creates a client that uses GraphQL API to retrieve branches, branch protections, collaborators and teams.
+func NewGraphQL(appID, installID int64, privateKey []byte, options ...Option) (Client, error) {
+	ghClient, err := newGitHubClient(appID, installID, privateKey, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphqlClient{
+		client: &client{
+			client: ghClient,
+		},
+		batches: make(map[string]*graphqlBatch),
+	}, nil
+}
+
+func repoKey(owner, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []*graphqlError `json:"errors"`
+}
+
+type graphqlError struct {
+	Type    string        `json:"type"`
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+type graphqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// query sends GraphQL query. Errors of a part of fields are ignored and the fields are null in data.
+func (x *graphqlClient) query(ctx *types.Context, query string, variables map[string]interface{}, data interface{}) error {
+	req, err := x.client.client.NewRequest(http.MethodPost, "graphql", map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+
+	var got graphqlResponse
+	resp, err := x.client.client.Do(ctx, req, &got)
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return types.ErrUnexpectedGitHubResp.New().With("code", resp.StatusCode)
+	}
+
+	for _, e := range got.Errors {
+		utils.Logger.With("type", e.Type).With("message", e.Message).With("path", e.Path).Debug("GraphQL query partially failed")
+	}
+	if len(got.Data) == 0 || string(got.Data) == "null" {
+		return types.ErrUnexpectedGitHubResp.New().With("errors", got.Errors)
+	}
+
+	if err := json.Unmarshal(got.Data, data); err != nil {
+		return goerr.Wrap(err)
+	}
+	return nil
+}
+
+func (x *graphqlClient) GetRepos(ctx *types.Context, owner string) ([]*github.Repository, error) {
+	repos, err := x.client.GetRepos(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	var batch *graphqlBatch
+	for i, repo := range repos {
+		if i%graphqlBatchSize == 0 {
+			batch = &graphqlBatch{}
+		}
+		batch.ids = append(batch.ids, repo.GetNodeID())
+		x.batches[repoKey(repo.GetOwner().GetLogin(), repo.GetName())] = batch
+	}
+
+	return repos, nil
+}
+
+// lookup returns data of the repository. It returns nil if the repository is not listed by GetRepos.
+func (x *graphqlClient) lookup(ctx *types.Context, owner, repo string) (*graphqlRepoData, error) {
+	x.mutex.Lock()
+	batch, ok := x.batches[repoKey(owner, repo)]
+	x.mutex.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	batch.once.Do(func() {
+		batch.repos, batch.err = x.queryRepos(ctx, batch.ids)
+	})
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	return batch.repos[repoKey(owner, repo)], nil
+}
+
+const graphqlReposQuery = `query($ids: [ID!]!) {
+  nodes(ids: $ids) {
+    ... on Repository {
+      nameWithOwner
+      rulesets(first: 1, includeParents: true) { totalCount }
+      refs(refPrefix: "refs/heads/", first: 100) {
+        pageInfo { hasNextPage }
+        nodes {
+          name
+          target { oid }
+          branchProtectionRule {
+            requiresApprovingReviews
+            requiredApprovingReviewCount
+            dismissesStaleReviews
+            requiresCodeOwnerReviews
+            restrictsReviewDismissals
+            requiresStatusChecks
+            requiresStrictStatusChecks
+            requiredStatusCheckContexts
+            isAdminEnforced
+            restrictsPushes
+            requiresLinearHistory
+            allowsForcePushes
+            allowsDeletions
+            requiresConversationResolution
+          }
+        }
+      }
+      allCollaborators: collaborators(first: 100, affiliation: ALL) { ...collaborators }
+      directCollaborators: collaborators(first: 100, affiliation: DIRECT) { ...collaborators }
+    }
+  }
+}
+
+fragment collaborators on RepositoryCollaboratorConnection {
+  pageInfo { hasNextPage }
+  edges {
+    permission
+    node { id databaseId login url avatarUrl }
+  }
+}`
+
+type graphqlRepository struct {
+	NameWithOwner string `json:"nameWithOwner"`
+	Rulesets      *struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"rulesets"`
+	Refs *struct {
+		PageInfo graphqlPageInfo `json:"pageInfo"`
+		Nodes    []*graphqlRef   `json:"nodes"`
+	} `json:"refs"`
+	AllCollaborators    *graphqlCollaborators `json:"allCollaborators"`
+	DirectCollaborators *graphqlCollaborators `json:"directCollaborators"`
+}
+
+type graphqlRef struct {
+	Name   string `json:"name"`
+	Target struct {
+		OID string `json:"oid"`
+	} `json:"target"`
+	BranchProtectionRule *graphqlBranchProtectionRule `json:"branchProtectionRule"`
+}
+
+type graphqlBranchProtectionRule struct {
+	RequiresApprovingReviews       bool     `json:"requiresApprovingReviews"`
+	RequiredApprovingReviewCount   int      `json:"requiredApprovingReviewCount"`
+	DismissesStaleReviews          bool     `json:"dismissesStaleReviews"`
+	RequiresCodeOwnerReviews       bool     `json:"requiresCodeOwnerReviews"`
+	RestrictsReviewDismissals      bool     `json:"restrictsReviewDismissals"`
+	RequiresStatusChecks           bool     `json:"requiresStatusChecks"`
+	RequiresStrictStatusChecks     bool     `json:"requiresStrictStatusChecks"`
+	RequiredStatusCheckContexts    []string `json:"requiredStatusCheckContexts"`
+	IsAdminEnforced                bool     `json:"isAdminEnforced"`
+	RestrictsPushes                bool     `json:"restrictsPushes"`
+	RequiresLinearHistory          bool     `json:"requiresLinearHistory"`
+	AllowsForcePushes              bool     `json:"allowsForcePushes"`
+	AllowsDeletions                bool     `json:"allowsDeletions"`
+	RequiresConversationResolution bool     `json:"requiresConversationResolution"`
+}
+
+type graphqlCollaborators struct {
+	PageInfo graphqlPageInfo `json:"pageInfo"`
+	Edges    []*struct {
+		Permission string `json:"permission"`
+		Node       struct {
+			ID         string `json:"id"`
+			DatabaseID int64  `json:"databaseId"`
+			Login      string `json:"login"`
+			URL        string `json:"url"`
+			AvatarURL  string `json:"avatarUrl"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+func (x *graphqlClient) queryRepos(ctx *types.Context, ids []string) (map[string]*graphqlRepoData, error) {
+	var data struct {
+		Nodes []*graphqlRepository `json:"nodes"`
+	}
+	if err := x.query(ctx, graphqlReposQuery, map[string]interface{}{"ids": ids}, &data); err != nil {
+		return nil, err
+	}
+	utils.Logger.With("repos", len(ids)).Trace("retrieved repository data by GraphQL")
+
+	repos := make(map[string]*graphqlRepoData)
+	for _, node := range data.Nodes {
+		if node == nil || node.NameWithOwner == "" {
+			continue
+		}
+
+		repo := &graphqlRepoData{
+			collaborators:       node.AllCollaborators.users(),
+			directCollaborators: node.DirectCollaborators.users(),
+		}
+		// protected field of REST API reflects rulesets as well as branch protection rules
+		if node.Refs != nil && !node.Refs.PageInfo.HasNextPage && node.Rulesets != nil && node.Rulesets.TotalCount == 0 {
+			repo.branches = []*github.Branch{}
+			repo.protections = make(map[string]*github.Protection)
+			for _, ref := range node.Refs.Nodes {
+				repo.branches = append(repo.branches, &github.Branch{
+					Name:      github.String(ref.Name),
+					Commit:    &github.RepositoryCommit{SHA: github.String(ref.Target.OID)},
+					Protected: github.Bool(ref.BranchProtectionRule != nil),
+				})
+				if protection := ref.BranchProtectionRule.protection(); protection != nil {
+					repo.protections[ref.Name] = protection
+				}
+			}
+		}
+
+		repos[strings.ToLower(node.NameWithOwner)] = repo
+	}
+
+	return repos, nil
+}
+
+// protection converts rule to branch protection of REST API. It returns nil if the rule has push or review dismissal restrictions because actors of them are not retrieved.
+func (x *graphqlBranchProtectionRule) protection() *github.Protection {
+	if x == nil || x.RestrictsPushes || x.RestrictsReviewDismissals {
+		return nil
+	}
+
+	protection := &github.Protection{
+		EnforceAdmins:                  &github.AdminEnforcement{Enabled: x.IsAdminEnforced},
+		RequireLinearHistory:           &github.RequireLinearHistory{Enabled: x.RequiresLinearHistory},
+		AllowForcePushes:               &github.AllowForcePushes{Enabled: x.AllowsForcePushes},
+		AllowDeletions:                 &github.AllowDeletions{Enabled: x.AllowsDeletions},
+		RequiredConversationResolution: &github.RequiredConversationResolution{Enabled: x.RequiresConversationResolution},
+	}
+	if x.RequiresApprovingReviews {
+		protection.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcement{
+			DismissStaleReviews:          x.DismissesStaleReviews,
+			RequireCodeOwnerReviews:      x.RequiresCodeOwnerReviews,
+			RequiredApprovingReviewCount: x.RequiredApprovingReviewCount,
+		}
+	}
+	if x.RequiresStatusChecks {
+		contexts := x.RequiredStatusCheckContexts
+		if contexts == nil {
+			contexts = []string{}
+		}
+		protection.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   x.RequiresStrictStatusChecks,
+			Contexts: contexts,
+		}
+	}
+
+	return protection
+}
+
+// users converts collaborators to users of REST API. It returns nil if collaborators are not available or have next page.
+func (x *graphqlCollaborators) users() []*github.User {
+	if x == nil || x.PageInfo.HasNextPage {
+		return nil
+	}
+
+	users := []*github.User{}
+	for _, edge := range x.Edges {
+		users = append(users, &github.User{
+			ID:          github.Int64(edge.Node.DatabaseID),
+			NodeID:      github.String(edge.Node.ID),
+			Login:       github.String(edge.Node.Login),
+			HTMLURL:     github.String(edge.Node.URL),
+			AvatarURL:   github.String(edge.Node.AvatarURL),
+			Type:        github.String("User"),
+			Permissions: model.PermissionToMap(model.NormalizePermission(edge.Permission)),
+		})
+	}
+	return users
+}
+
+func (x *graphqlClient) GetBranches(ctx *types.Context, owner, repo string) ([]*github.Branch, error) {
+	data, err := x.lookup(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil || data.branches == nil {
+		return x.client.GetBranches(ctx, owner, repo)
+	}
+	if len(data.branches) == 0 {
+		return nil, nil // same as REST API
+	}
+	return data.branches, nil
+}
+
+func (x *graphqlClient) GetBranchProtection(ctx *types.Context, owner, repo, branch string) (*github.Protection, error) {
+	data, err := x.lookup(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if protection, ok := data.protections[branch]; ok {
+			return protection, nil
+		}
+	}
+	return x.client.GetBranchProtection(ctx, owner, repo, branch)
+}
+
+func (x *graphqlClient) GetCollaborators(ctx *types.Context, owner, repo string) ([]*github.User, error) {
+	data, err := x.lookup(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil || data.collaborators == nil {
+		return x.client.GetCollaborators(ctx, owner, repo)
+	}
+	if len(data.collaborators) == 0 {
+		return nil, nil // same as REST API
+	}
+	return data.collaborators, nil
+}
+
+func (x *graphqlClient) GetDirectCollaborators(ctx *types.Context, owner, repo string) ([]*github.User, error) {
+	data, err := x.lookup(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil || data.directCollaborators == nil {
+		return x.client.GetDirectCollaborators(ctx, owner, repo)
+	}
+	if len(data.directCollaborators) == 0 {
+		return nil, nil // same as REST API
+	}
+	return data.directCollaborators, nil
+}
+
+const graphqlTeamsQuery = `query($org: String!, $after: String) {
+  organization(login: $org) {
+    teams(first: 50, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        id databaseId slug name description privacy url
+        parentTeam { id databaseId slug name }
+        repositories(first: 100) { ...repositories }
+      }
+    }
+  }
+}
+
+fragment repositories on TeamRepositoryConnection {
+  pageInfo { hasNextPage endCursor }
+  edges {
+    permission
+    node { nameWithOwner }
+  }
+}`
+
+const graphqlTeamReposQuery = `query($org: String!, $slug: String!, $after: String) {
+  organization(login: $org) {
+    team(slug: $slug) {
+      repositories(first: 100, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        edges {
+          permission
+          node { nameWithOwner }
+        }
+      }
+    }
+  }
+}`
+
+type graphqlTeam struct {
+	ID          string `json:"id"`
+	DatabaseID  int64  `json:"databaseId"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Privacy     string `json:"privacy"`
+	URL         string `json:"url"`
+	ParentTeam  *struct {
+		ID         string `json:"id"`
+		DatabaseID int64  `json:"databaseId"`
+		Slug       string `json:"slug"`
+		Name       string `json:"name"`
+	} `json:"parentTeam"`
+	Repositories *graphqlTeamRepos `json:"repositories"`
+}
+
+type graphqlTeamRepos struct {
+	PageInfo graphqlPageInfo `json:"pageInfo"`
+	Edges    []*struct {
+		Permission string `json:"permission"`
+		Node       struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"node"`
+	} `json:"edges"`
+}
+
+// queryTeams returns teams of each repository in organization. It returns nil if teams of the organization are not available.
+func (x *graphqlClient) queryTeams(ctx *types.Context, org string) (map[string][]*github.Team, error) {
+	result := make(map[string][]*github.Team)
+
+	var after *string
+	for {
+		var data struct {
+			Organization *struct {
+				Teams *struct {
+					PageInfo graphqlPageInfo `json:"pageInfo"`
+					Nodes    []*graphqlTeam  `json:"nodes"`
+				} `json:"teams"`
+			} `json:"organization"`
+		}
+		if err := x.query(ctx, graphqlTeamsQuery, map[string]interface{}{"org": org, "after": after}, &data); err != nil {
+			return nil, err
+		}
+		if data.Organization == nil || data.Organization.Teams == nil {
+			utils.Logger.With("org", org).Debug("teams are not available by GraphQL")
+			return nil, nil
+		}
+
+		for _, team := range data.Organization.Teams.Nodes {
+			repos := team.Repositories
+			for repos != nil {
+				for _, edge := range repos.Edges {
+					key := strings.ToLower(edge.Node.NameWithOwner)
+					result[key] = append(result[key], team.restTeam(edge.Permission))
+				}
+				if !repos.PageInfo.HasNextPage {
+					break
+				}
+
+				next, err := x.queryTeamRepos(ctx, org, team.Slug, repos.PageInfo.EndCursor)
+				if err != nil {
+					return nil, err
+				}
+				repos = next
+			}
+			if repos == nil {
+				return nil, nil
+			}
+		}
+
+		if !data.Organization.Teams.PageInfo.HasNextPage {
+			break
+		}
+		after = github.String(data.Organization.Teams.PageInfo.EndCursor)
+	}
+
+	return result, nil
+}
+
+func (x *graphqlClient) queryTeamRepos(ctx *types.Context, org, slug, after string) (*graphqlTeamRepos, error) {
+	var data struct {
+		Organization *struct {
+			Team *struct {
+				Repositories *graphqlTeamRepos `json:"repositories"`
+			} `json:"team"`
+		} `json:"organization"`
+	}
+	vars := map[string]interface{}{"org": org, "slug": slug, "after": after}
+	if err := x.query(ctx, graphqlTeamReposQuery, vars, &data); err != nil {
+		return nil, err
+	}
+	if data.Organization == nil || data.Organization.Team == nil {
+		return nil, nil
+	}
+	return data.Organization.Team.Repositories, nil
+}
+
+// restTeam converts team to team of REST API with permission for a repository.
+func (x *graphqlTeam) restTeam(permission string) *github.Team {
+	perm := model.NormalizePermission(permission)
+	team := &github.Team{
+		ID:          github.Int64(x.DatabaseID),
+		NodeID:      github.String(x.ID),
+		Slug:        github.String(x.Slug),
+		Name:        github.String(x.Name),
+		Description: github.String(x.Description),
+		HTMLURL:     github.String(x.URL),
+		Permission:  github.String(restPermission(perm)),
+		Permissions: model.PermissionToMap(perm),
+	}
+
+	switch x.Privacy {
+	case "VISIBLE":
+		team.Privacy = github.String("closed")
+	case "SECRET":
+		team.Privacy = github.String("secret")
+	}
+	if x.ParentTeam != nil {
+		team.Parent = &github.Team{
+			ID:     github.Int64(x.ParentTeam.DatabaseID),
+			NodeID: github.String(x.ParentTeam.ID),
+			Slug:   github.String(x.ParentTeam.Slug),
+			Name:   github.String(x.ParentTeam.Name),
+		}
+	}
+	return team
+}
+
+// restPermission converts normalized permission to legacy permission name of REST API.
+func restPermission(perm string) string {
+	switch perm {
+	case model.PermissionRead:
+		return "pull"
+	case model.PermissionWrite:
+		return "push"
+	default:
+		return perm
+	}
+}
+
+func (x *graphqlClient) GetTeams(ctx *types.Context, owner, repo string) ([]*github.Team, error) {
+	x.teamsOnce.Do(func() {
+		x.teams, x.teamsErr = x.queryTeams(ctx, owner)
+	})
+	if x.teamsErr != nil {
+		return nil, x.teamsErr
+	}
+	if x.teams == nil {
+		return x.client.GetTeams(ctx, owner, repo)
+	}
+	return x.teams[repoKey(owner, repo)], nil
+}
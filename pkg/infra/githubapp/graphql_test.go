@@ -0,0 +1,211 @@
+package githubapp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// GraphQL responses below have same data as REST responses
+const (
+	testRESTRepos = `[
+  {"id": 1, "node_id": "R_blue", "name": "blue", "full_name": "my-org/blue", "owner": {"login": "my-org"}},
+  {"id": 2, "node_id": "R_red", "name": "red", "full_name": "my-org/red", "owner": {"login": "my-org"}}
+]`
+
+	testGraphQLRepos = `{"data": {"nodes": [
+  {
+    "nameWithOwner": "my-org/blue",
+    "rulesets": {"totalCount": 0},
+    "refs": {"pageInfo": {"hasNextPage": false}, "nodes": [
+      {"name": "main", "target": {"oid": "a1"}, "branchProtectionRule": {
+        "requiresApprovingReviews": true, "requiredApprovingReviewCount": 2,
+        "dismissesStaleReviews": true, "requiresCodeOwnerReviews": false, "restrictsReviewDismissals": false,
+        "requiresStatusChecks": true, "requiresStrictStatusChecks": true, "requiredStatusCheckContexts": ["ci"],
+        "isAdminEnforced": true, "restrictsPushes": false, "requiresLinearHistory": true,
+        "allowsForcePushes": false, "allowsDeletions": false, "requiresConversationResolution": true
+      }},
+      {"name": "release", "target": {"oid": "b2"}, "branchProtectionRule": {
+        "requiresApprovingReviews": false, "requiresStatusChecks": false, "restrictsPushes": true
+      }},
+      {"name": "dev", "target": {"oid": "c3"}, "branchProtectionRule": null}
+    ]},
+    "allCollaborators": {"pageInfo": {"hasNextPage": false}, "edges": [
+      {"permission": "ADMIN", "node": {"id": "U_alice", "databaseId": 10, "login": "alice", "url": "https://github.com/alice", "avatarUrl": "https://avatars.example.com/alice"}},
+      {"permission": "WRITE", "node": {"id": "U_bob", "databaseId": 11, "login": "bob", "url": "https://github.com/bob", "avatarUrl": "https://avatars.example.com/bob"}}
+    ]},
+    "directCollaborators": {"pageInfo": {"hasNextPage": false}, "edges": []}
+  },
+  {
+    "nameWithOwner": "my-org/red",
+    "rulesets": {"totalCount": 1},
+    "refs": {"pageInfo": {"hasNextPage": false}, "nodes": [
+      {"name": "main", "target": {"oid": "d4"}, "branchProtectionRule": null}
+    ]},
+    "allCollaborators": {"pageInfo": {"hasNextPage": false}, "edges": []},
+    "directCollaborators": {"pageInfo": {"hasNextPage": false}, "edges": []}
+  }
+]}}`
+
+	testGraphQLTeams = `{"data": {"organization": {"teams": {
+  "pageInfo": {"hasNextPage": false, "endCursor": "x"},
+  "nodes": [
+    {
+      "id": "T_eng", "databaseId": 100, "slug": "eng", "name": "Eng", "description": "engineers",
+      "privacy": "VISIBLE", "url": "https://github.com/orgs/my-org/teams/eng",
+      "parentTeam": {"id": "T_all", "databaseId": 99, "slug": "all", "name": "All"},
+      "repositories": {"pageInfo": {"hasNextPage": false}, "edges": [
+        {"permission": "WRITE", "node": {"nameWithOwner": "my-org/blue"}}
+      ]}
+    },
+    {
+      "id": "T_all", "databaseId": 99, "slug": "all", "name": "All", "description": "",
+      "privacy": "SECRET", "url": "https://github.com/orgs/my-org/teams/all",
+      "parentTeam": null,
+      "repositories": {"pageInfo": {"hasNextPage": false}, "edges": [
+        {"permission": "READ", "node": {"nameWithOwner": "my-org/blue"}},
+        {"permission": "MAINTAIN", "node": {"nameWithOwner": "my-org/red"}}
+      ]}
+    }
+  ]
+}}}}`
+)
+
+func newGraphQLTestMux(t *testing.T, restCalls map[string]int) *http.ServeMux {
+	rest := map[string]string{
+		"/orgs/my-org/repos": testRESTRepos,
+		"/repos/my-org/blue/branches": `[
+  {"name": "main", "commit": {"sha": "a1"}, "protected": true},
+  {"name": "release", "commit": {"sha": "b2"}, "protected": true},
+  {"name": "dev", "commit": {"sha": "c3"}, "protected": false}
+]`,
+		"/repos/my-org/blue/branches/main/protection": `{
+  "required_pull_request_reviews": {"dismiss_stale_reviews": true, "require_code_owner_reviews": false, "required_approving_review_count": 2},
+  "required_status_checks": {"strict": true, "contexts": ["ci"]},
+  "enforce_admins": {"enabled": true},
+  "required_linear_history": {"enabled": true},
+  "allow_force_pushes": {"enabled": false},
+  "allow_deletions": {"enabled": false},
+  "required_conversation_resolution": {"enabled": true}
+}`,
+		"/repos/my-org/blue/branches/release/protection": `{
+  "enforce_admins": {"enabled": false},
+  "restrictions": {"users": [{"login": "alice"}], "teams": [], "apps": []}
+}`,
+		"/repos/my-org/blue/collaborators": `[
+  {"id": 10, "node_id": "U_alice", "login": "alice", "html_url": "https://github.com/alice", "avatar_url": "https://avatars.example.com/alice", "type": "User",
+   "permissions": {"pull": true, "triage": true, "push": true, "maintain": true, "admin": true}},
+  {"id": 11, "node_id": "U_bob", "login": "bob", "html_url": "https://github.com/bob", "avatar_url": "https://avatars.example.com/bob", "type": "User",
+   "permissions": {"pull": true, "triage": true, "push": true, "maintain": false, "admin": false}}
+]`,
+		"/repos/my-org/blue/teams": `[
+  {"id": 100, "node_id": "T_eng", "slug": "eng", "name": "Eng", "description": "engineers", "privacy": "closed",
+   "html_url": "https://github.com/orgs/my-org/teams/eng", "permission": "push",
+   "permissions": {"pull": true, "triage": true, "push": true, "maintain": false, "admin": false},
+   "parent": {"id": 99, "node_id": "T_all", "slug": "all", "name": "All"}},
+  {"id": 99, "node_id": "T_all", "slug": "all", "name": "All", "description": "", "privacy": "secret",
+   "html_url": "https://github.com/orgs/my-org/teams/all", "permission": "pull",
+   "permissions": {"pull": true, "triage": false, "push": false, "maintain": false, "admin": false}}
+]`,
+		"/repos/my-org/red/branches": `[
+  {"name": "main", "commit": {"sha": "d4"}, "protected": true}
+]`,
+	}
+
+	mux := http.NewServeMux()
+	for path, body := range rest {
+		body := body
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			restCalls[r.URL.Path]++
+			// only all collaborators are defined
+			if r.URL.Query().Get("affiliation") == "direct" {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+			_, _ = w.Write([]byte(body))
+		})
+	}
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch {
+		case strings.Contains(req.Query, "nodes(ids:"):
+			_, _ = w.Write([]byte(testGraphQLRepos))
+		case strings.Contains(req.Query, "teams(first:"):
+			_, _ = w.Write([]byte(testGraphQLTeams))
+		default:
+			t.Errorf("unexpected query: %s", req.Query)
+		}
+	})
+	return mux
+}
+
+func TestGraphQLClientOffline(t *testing.T) {
+	restCalls := map[string]int{}
+	rest, gql := newTestClient(t, newGraphQLTestMux(t, restCalls))
+	ctx := types.NewContext()
+
+	restRepos, err := rest.GetRepos(ctx, "my-org")
+	require.NoError(t, err)
+	gqlRepos, err := gql.GetRepos(ctx, "my-org")
+	require.NoError(t, err)
+	require.Equal(t, restRepos, gqlRepos)
+
+	t.Run("same data as REST API", func(t *testing.T) {
+		expected, err := rest.GetBranches(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		actual, err := gql.GetBranches(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+
+		for _, branch := range []string{"main", "release"} {
+			expected, err := rest.GetBranchProtection(ctx, "my-org", "blue", branch)
+			require.NoError(t, err)
+			actual, err := gql.GetBranchProtection(ctx, "my-org", "blue", branch)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, branch)
+		}
+
+		expectedUsers, err := rest.GetCollaborators(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		actualUsers, err := gql.GetCollaborators(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.Equal(t, expectedUsers, actualUsers)
+
+		expectedUsers, err = rest.GetDirectCollaborators(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		actualUsers, err = gql.GetDirectCollaborators(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.Equal(t, expectedUsers, actualUsers)
+
+		expectedTeams, err := rest.GetTeams(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		actualTeams, err := gql.GetTeams(ctx, "my-org", "blue")
+		require.NoError(t, err)
+		assert.Equal(t, expectedTeams, actualTeams)
+	})
+
+	t.Run("only protection with push restrictions is retrieved by REST API", func(t *testing.T) {
+		// counts include calls of REST client
+		assert.Equal(t, 2, restCalls["/repos/my-org/blue/branches/release/protection"])
+		assert.Equal(t, 1, restCalls["/repos/my-org/blue/branches/main/protection"])
+		assert.Equal(t, 1, restCalls["/repos/my-org/blue/branches"])
+		assert.Equal(t, 2, restCalls["/repos/my-org/blue/collaborators"])
+		assert.Equal(t, 1, restCalls["/repos/my-org/blue/teams"])
+	})
+
+	t.Run("branches of repository having rulesets are retrieved by REST API", func(t *testing.T) {
+		branches, err := gql.GetBranches(ctx, "my-org", "red")
+		require.NoError(t, err)
+		require.Len(t, branches, 1)
+		assert.True(t, branches[0].GetProtected())
+		assert.Equal(t, 1, restCalls["/repos/my-org/red/branches"])
+	})
+}
@@ -0,0 +1,149 @@
+package githubapp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+)
+
+// Feedback is result of a request to adjust concurrency of AdaptiveLimiter.
+type Feedback int
+
+const (
+	// FeedbackSuccess is a successful response.
+	FeedbackSuccess Feedback = iota
+	// FeedbackBudgetLow is a successful response, but remaining budget of rate limit is low.
+	FeedbackBudgetLow
+	// FeedbackServerError is a 5xx response.
+	FeedbackServerError
+	// FeedbackThrottled is a response of secondary rate limit.
+	FeedbackThrottled
+)
+
+// AdaptiveLimiter limits number of concurrent requests to GitHub API. The limit is adjusted by AIMD (additive increase, multiplicative decrease): it grows by 1 after a window of successful requests (same number as the current limit) while latency and rate limit budget are healthy, shrinks by 1 on slow window, low budget or server error, and is halved on secondary rate limit.
+type AdaptiveLimiter struct {
+	min, max         int
+	latencyThreshold time.Duration
+
+	mutex     sync.Mutex
+	limit     int
+	inFlight  int
+	successes int
+	slow      int
+	notify    chan struct{}
+}
+
+type LimiterOption func(x *AdaptiveLimiter)
+
+// WithLatencyThreshold sets latency to regard a request as slow. Default is 2 seconds.
+func WithLatencyThreshold(d time.Duration) LimiterOption {
+	return func(x *AdaptiveLimiter) {
+		x.latencyThreshold = d
+	}
+}
+
+// NewAdaptiveLimiter creates a limiter starting with initial concurrency and growing up to max.
+func NewAdaptiveLimiter(initial, max int, options ...LimiterOption) *AdaptiveLimiter {
+	if max < 1 {
+		max = 1
+	}
+	if initial < 1 {
+		initial = 1
+	}
+	if max < initial {
+		initial = max
+	}
+
+	x := &AdaptiveLimiter{
+		min:              1,
+		max:              max,
+		latencyThreshold: 2 * time.Second,
+		limit:            initial,
+		notify:           make(chan struct{}),
+	}
+	for _, opt := range options {
+		opt(x)
+	}
+	return x
+}
+
+// Limit returns current concurrency.
+func (x *AdaptiveLimiter) Limit() int {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return x.limit
+}
+
+// Acquire waits until number of requests in flight is less than current limit.
+func (x *AdaptiveLimiter) Acquire(ctx context.Context) error {
+	for {
+		x.mutex.Lock()
+		if x.inFlight < x.limit {
+			x.inFlight++
+			x.mutex.Unlock()
+			return nil
+		}
+		notify := x.notify
+		x.mutex.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return goerr.Wrap(ctx.Err())
+		}
+	}
+}
+
+// Release returns a slot acquired by Acquire and adjusts limit by latency and feedback of the request.
+func (x *AdaptiveLimiter) Release(latency time.Duration, feedback Feedback) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.inFlight--
+	prev := x.limit
+
+	switch feedback {
+	case FeedbackThrottled:
+		x.setLimit(x.limit / 2)
+
+	case FeedbackServerError:
+		x.setLimit(x.limit - 1)
+
+	default:
+		x.successes++
+		if feedback == FeedbackBudgetLow || latency > x.latencyThreshold {
+			x.slow++
+		}
+		if x.successes >= x.limit {
+			if x.slow > 0 {
+				x.setLimit(x.limit - 1)
+			} else {
+				x.setLimit(x.limit + 1)
+			}
+		}
+	}
+
+	if x.limit != prev {
+		utils.Logger.With("limit", x.limit).With("prev", prev).Debug("changed concurrency of GitHub API requests")
+	}
+
+	close(x.notify)
+	x.notify = make(chan struct{})
+}
+
+// setLimit updates limit within min and max and starts a new window.
+func (x *AdaptiveLimiter) setLimit(n int) {
+	if n < x.min {
+		n = x.min
+	}
+	if n > x.max {
+		n = x.max
+	}
+	x.limit = n
+	x.successes = 0
+	x.slow = 0
+}
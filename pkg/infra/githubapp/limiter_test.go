@@ -0,0 +1,90 @@
+package githubapp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	// run n requests sequentially with the feedback
+	run := func(t *testing.T, limiter *githubapp.AdaptiveLimiter, n int, latency time.Duration, feedback githubapp.Feedback) {
+		for i := 0; i < n; i++ {
+			require.NoError(t, limiter.Acquire(ctx))
+			limiter.Release(latency, feedback)
+		}
+	}
+
+	t.Run("grow after a window of healthy requests", func(t *testing.T) {
+		limiter := githubapp.NewAdaptiveLimiter(2, 4)
+		run(t, limiter, 2, time.Millisecond, githubapp.FeedbackSuccess)
+		assert.Equal(t, 3, limiter.Limit())
+		run(t, limiter, 3, time.Millisecond, githubapp.FeedbackSuccess)
+		assert.Equal(t, 4, limiter.Limit())
+		run(t, limiter, 10, time.Millisecond, githubapp.FeedbackSuccess)
+		assert.Equal(t, 4, limiter.Limit(), "not exceed max")
+	})
+
+	t.Run("shrink by slow requests and low budget", func(t *testing.T) {
+		limiter := githubapp.NewAdaptiveLimiter(4, 8, githubapp.WithLatencyThreshold(time.Second))
+		run(t, limiter, 3, time.Millisecond, githubapp.FeedbackSuccess)
+		run(t, limiter, 1, 3*time.Second, githubapp.FeedbackSuccess)
+		assert.Equal(t, 3, limiter.Limit())
+
+		run(t, limiter, 3, time.Millisecond, githubapp.FeedbackBudgetLow)
+		assert.Equal(t, 2, limiter.Limit())
+	})
+
+	t.Run("halve by secondary rate limit", func(t *testing.T) {
+		limiter := githubapp.NewAdaptiveLimiter(8, 8)
+		run(t, limiter, 1, time.Millisecond, githubapp.FeedbackThrottled)
+		assert.Equal(t, 4, limiter.Limit())
+		run(t, limiter, 5, time.Millisecond, githubapp.FeedbackThrottled)
+		assert.Equal(t, 1, limiter.Limit(), "not less than 1")
+	})
+
+	t.Run("shrink by server error", func(t *testing.T) {
+		limiter := githubapp.NewAdaptiveLimiter(3, 8)
+		run(t, limiter, 1, time.Millisecond, githubapp.FeedbackServerError)
+		assert.Equal(t, 2, limiter.Limit())
+	})
+
+	t.Run("block until a slot is released", func(t *testing.T) {
+		limiter := githubapp.NewAdaptiveLimiter(1, 1)
+		require.NoError(t, limiter.Acquire(ctx))
+
+		acquired := make(chan struct{})
+		go func() {
+			require.NoError(t, limiter.Acquire(ctx))
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("acquired over limit")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		limiter.Release(time.Millisecond, githubapp.FeedbackSuccess)
+		select {
+		case <-acquired:
+		case <-time.After(time.Second):
+			t.Fatal("not acquired after release")
+		}
+	})
+
+	t.Run("cancel waiting", func(t *testing.T) {
+		limiter := githubapp.NewAdaptiveLimiter(1, 1)
+		require.NoError(t, limiter.Acquire(ctx))
+
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		assert.Error(t, limiter.Acquire(cctx))
+	})
+}
@@ -14,11 +14,13 @@ import (
 
 type loaderClient struct {
 	input map[string]*model.RegoInput
+	org   map[string]*model.RegoOrgInput
 }
 
 func NewloaderClient(dir string) (*loaderClient, error) {
 	client := &loaderClient{
 		input: make(map[string]*model.RegoInput),
+		org:   make(map[string]*model.RegoOrgInput),
 	}
 	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -37,6 +39,15 @@ func NewloaderClient(dir string) (*loaderClient, error) {
 		}
 		defer fd.Close()
 
+		if filepath.Base(filepath.Dir(path)) == types.DumpOrgDir {
+			org := model.RegoOrgInput{}
+			if err := json.NewDecoder(fd).Decode(&org); err != nil {
+				return goerr.Wrap(err)
+			}
+			client.org[org.Org.GetLogin()] = &org
+			return nil
+		}
+
 		input := model.RegoInput{}
 		if err := json.NewDecoder(fd).Decode(&input); err != nil {
 			return goerr.Wrap(err)
@@ -59,6 +70,254 @@ func (x *loaderClient) GetRepos(ctx *types.Context, owner string) ([]*github.Rep
 	return repos, nil
 }
 
+// lookupOrg returns organization data. It returns the only one loaded organization data if org is empty because owner is not required with loader.
+func (x *loaderClient) lookupOrg(org string) *model.RegoOrgInput {
+	if org == "" && len(x.org) == 1 {
+		for _, v := range x.org {
+			return v
+		}
+	}
+	return x.org[org]
+}
+
+func (x *loaderClient) GetOrganization(ctx *types.Context, org string) (*github.Organization, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.Org, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgHooks(ctx *types.Context, org string) ([]*github.Hook, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return restoreHooks(v.Hooks), nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgHookDeliveries(ctx *types.Context, org string, hookID int64) ([]*github.HookDelivery, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return restoreHookDeliveries(v.Hooks, hookID), nil
+	}
+	return nil, nil
+}
+
+func restoreHooks(hooks []*model.RegoInputHook) []*github.Hook {
+	var resp []*github.Hook
+	for _, hook := range hooks {
+		resp = append(resp, &hook.Hook)
+	}
+	return resp
+}
+
+func restoreHookDeliveries(hooks []*model.RegoInputHook, hookID int64) []*github.HookDelivery {
+	for _, hook := range hooks {
+		if hook.GetID() == hookID && hook.Deliveries != nil {
+			if hook.Deliveries.Recent == nil {
+				return []*github.HookDelivery{}
+			}
+			return hook.Deliveries.Recent
+		}
+	}
+	return nil
+}
+
+func (x *loaderClient) GetOrgActionsPermissions(ctx *types.Context, org string) (*github.ActionsPermissions, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.ActionsPermissions, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgActionsAllowed(ctx *types.Context, org string) (*github.ActionsAllowed, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.ActionsAllowed, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgSAMLSSOEnabled(ctx *types.Context, org string) (*bool, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.SAMLSSOEnabled, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgMembers(ctx *types.Context, org, role string) ([]*github.User, error) {
+	var users []*github.User
+	if v := x.lookupOrg(org); v != nil {
+		for _, member := range v.Members {
+			if member.Role == role {
+				users = append(users, &member.User)
+			}
+		}
+	}
+	return users, nil
+}
+
+func (x *loaderClient) GetOrgMembersWithout2FA(ctx *types.Context, org string) ([]*github.User, error) {
+	v := x.lookupOrg(org)
+	if v == nil {
+		return nil, nil
+	}
+
+	users := []*github.User{}
+	for _, member := range v.Members {
+		if member.TwoFactorDisabled == nil {
+			return nil, nil
+		}
+		if *member.TwoFactorDisabled {
+			users = append(users, &member.User)
+		}
+	}
+	return users, nil
+}
+
+func (x *loaderClient) GetOutsideCollaborators(ctx *types.Context, org string) ([]*github.User, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.OutsideCollaborators, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgInvitations(ctx *types.Context, org string) ([]*github.Invitation, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.Invitations, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgTeams(ctx *types.Context, org string) ([]*github.Team, error) {
+	var teams []*github.Team
+	if v := x.lookupOrg(org); v != nil {
+		for _, team := range v.Teams {
+			teams = append(teams, &team.Team)
+		}
+	}
+	return teams, nil
+}
+
+func (x *loaderClient) GetTeamMembers(ctx *types.Context, org, slug, role string) ([]*github.User, error) {
+	v := x.lookupOrg(org)
+	if v == nil {
+		return nil, nil
+	}
+	for _, team := range v.Teams {
+		if team.GetSlug() != slug {
+			continue
+		}
+		switch role {
+		case "maintainer":
+			return team.Maintainers, nil
+		case "member":
+			maintainers := make(map[int64]bool)
+			for _, user := range team.Maintainers {
+				maintainers[user.GetID()] = true
+			}
+			var users []*github.User
+			for _, user := range team.Members {
+				if !maintainers[user.GetID()] {
+					users = append(users, user)
+				}
+			}
+			return users, nil
+		default:
+			return team.Members, nil
+		}
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgInstallations(ctx *types.Context, org string) ([]*model.AppInstallation, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.Installations, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgPropertySchema(ctx *types.Context, org string) ([]*model.CustomProperty, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.PropertySchema, nil
+	}
+	return nil, nil
+}
+
+// GetOrgPropertyValues restores custom property values from input.properties of repositories
+func (x *loaderClient) GetOrgPropertyValues(ctx *types.Context, org string) ([]*model.RepoCustomPropertyValues, error) {
+	if v := x.lookupOrg(org); v == nil || v.PropertySchema == nil {
+		return nil, nil
+	}
+
+	values := []*model.RepoCustomPropertyValues{}
+	for _, input := range x.input {
+		if input.Properties == nil {
+			continue
+		}
+		repoValues := &model.RepoCustomPropertyValues{
+			RepositoryID:       input.Repo.GetID(),
+			RepositoryName:     input.Repo.GetName(),
+			RepositoryFullName: input.Repo.GetFullName(),
+		}
+		for name, value := range input.Properties {
+			repoValues.Properties = append(repoValues.Properties, &model.CustomPropertyValue{
+				PropertyName: name,
+				Value:        value,
+			})
+		}
+		values = append(values, repoValues)
+	}
+	return values, nil
+}
+
+func (x *loaderClient) GetOrgRunners(ctx *types.Context, org string) ([]*github.Runner, error) {
+	if v := x.lookupOrg(org); v != nil {
+		return v.Runners, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetOrgRunnerGroups(ctx *types.Context, org string) ([]*model.RunnerGroup, error) {
+	v := x.lookupOrg(org)
+	if v == nil || v.RunnerGroups == nil {
+		return nil, nil
+	}
+	groups := []*model.RunnerGroup{}
+	for _, group := range v.RunnerGroups {
+		groups = append(groups, &group.RunnerGroup)
+	}
+	return groups, nil
+}
+
+func (x *loaderClient) lookupRunnerGroup(org string, groupID int64) *model.RegoInputRunnerGroup {
+	if v := x.lookupOrg(org); v != nil {
+		for _, group := range v.RunnerGroups {
+			if group.ID == groupID {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+func (x *loaderClient) GetRunnerGroupRunners(ctx *types.Context, org string, groupID int64) ([]*github.Runner, error) {
+	if group := x.lookupRunnerGroup(org, groupID); group != nil {
+		return group.Runners, nil
+	}
+	return nil, nil
+}
+
+// GetRunnerGroupRepos restores repositories that have only full name because only full names are dumped
+func (x *loaderClient) GetRunnerGroupRepos(ctx *types.Context, org string, groupID int64) ([]*github.Repository, error) {
+	group := x.lookupRunnerGroup(org, groupID)
+	if group == nil {
+		return nil, nil
+	}
+	var repos []*github.Repository
+	for _, name := range group.Repositories {
+		repos = append(repos, &github.Repository{FullName: github.String(name)})
+	}
+	return repos, nil
+}
+
 func (x *loaderClient) GetBranches(ctx *types.Context, owner string, repo string) ([]*github.Branch, error) {
 	branches := x.input[owner+"/"+repo].Branches
 	var resp []*github.Branch
@@ -84,10 +343,244 @@ func (x *loaderClient) GetCollaborators(ctx *types.Context, owner string, repo s
 	return x.input[owner+"/"+repo].Collaborators, nil
 }
 
+// GetDirectCollaborators restores direct collaborators from input.access because they are not dumped as is
+func (x *loaderClient) GetDirectCollaborators(ctx *types.Context, owner string, repo string) ([]*github.User, error) {
+	var users []*github.User
+	for _, access := range x.input[owner+"/"+repo].Access {
+		if access.Type != model.AccessTypeUser {
+			continue
+		}
+		for _, grant := range access.Grants {
+			if grant.Via == model.AccessViaDirect {
+				users = append(users, &github.User{
+					Login:       github.String(access.Login),
+					ID:          github.Int64(access.ID),
+					Permissions: model.PermissionToMap(grant.Permission),
+				})
+			}
+		}
+	}
+	return users, nil
+}
+
 func (x *loaderClient) GetHooks(ctx *types.Context, owner string, repo string) ([]*github.Hook, error) {
-	return x.input[owner+"/"+repo].Hooks, nil
+	return restoreHooks(x.input[owner+"/"+repo].Hooks), nil
+}
+
+func (x *loaderClient) GetHookDeliveries(ctx *types.Context, owner, repo string, hookID int64) ([]*github.HookDelivery, error) {
+	return restoreHookDeliveries(x.input[owner+"/"+repo].Hooks, hookID), nil
 }
 
 func (x *loaderClient) GetTeams(ctx *types.Context, owner string, repo string) ([]*github.Team, error) {
-	return x.input[owner+"/"+repo].Teams, nil
+	var teams []*github.Team
+	for _, team := range x.input[owner+"/"+repo].Teams {
+		teams = append(teams, &team.Team)
+	}
+	return teams, nil
+}
+
+func (x *loaderClient) GetEnvironments(ctx *types.Context, owner string, repo string) ([]*github.Environment, error) {
+	var resp []*github.Environment
+	for _, env := range x.input[owner+"/"+repo].Environments {
+		resp = append(resp, &env.Environment)
+	}
+	return resp, nil
+}
+
+func (x *loaderClient) lookupEnvironment(owner, repo, env string) *model.RegoInputEnvironment {
+	for _, e := range x.input[owner+"/"+repo].Environments {
+		if e.GetName() == env {
+			return e
+		}
+	}
+	return nil
+}
+
+func (x *loaderClient) GetDeploymentBranchPolicies(ctx *types.Context, owner, repo, env string) ([]*model.DeploymentBranchPolicy, error) {
+	if e := x.lookupEnvironment(owner, repo, env); e != nil {
+		return e.BranchPolicies, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetDeploymentProtectionRules(ctx *types.Context, owner, repo, env string) ([]*model.DeploymentProtectionRule, error) {
+	if e := x.lookupEnvironment(owner, repo, env); e != nil {
+		return e.CustomProtectionRules, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetSecurityAndAnalysis(ctx *types.Context, owner, repo string) (*model.SecurityAndAnalysis, error) {
+	if security := x.input[owner+"/"+repo].Security; security != nil {
+		return security.Analysis, nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetRepoRunners(ctx *types.Context, owner, repo string) ([]*github.Runner, error) {
+	if runners := x.input[owner+"/"+repo].Runners; runners != nil {
+		return runners.Repository, nil
+	}
+	return nil, nil
+}
+
+// GetCommits restores commits from input.commits, or only the last commit from input.activity if commit statistics were not retrieved
+func (x *loaderClient) GetCommits(ctx *types.Context, owner, repo, ref string, limit int) ([]*github.RepositoryCommit, error) {
+	input := x.input[owner+"/"+repo]
+
+	var commits []*github.RepositoryCommit
+	if input.Commits != nil {
+		for _, commit := range input.Commits.Commits {
+			commits = append(commits, commit.RepositoryCommit())
+		}
+	} else if input.Activity != nil && input.Activity.LastCommit != nil {
+		commits = append(commits, input.Activity.LastCommit.RepositoryCommit())
+	}
+
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, nil
+}
+
+func (x *loaderClient) GetOpenPullRequests(ctx *types.Context, owner, repo string) ([]*github.PullRequest, error) {
+	activity := x.input[owner+"/"+repo].Activity
+	if activity == nil {
+		return nil, nil
+	}
+	var pulls []*github.PullRequest
+	for _, pr := range activity.OpenPullRequests {
+		pr := pr
+		pulls = append(pulls, &github.PullRequest{
+			Number:    github.Int(pr.Number),
+			Title:     github.String(pr.Title),
+			User:      &github.User{Login: github.String(pr.User)},
+			Draft:     github.Bool(pr.Draft),
+			HTMLURL:   github.String(pr.HTMLURL),
+			CreatedAt: &pr.CreatedAt,
+			UpdatedAt: &pr.UpdatedAt,
+		})
+	}
+	return pulls, nil
+}
+
+func (x *loaderClient) GetLatestRelease(ctx *types.Context, owner, repo string) (*github.RepositoryRelease, error) {
+	activity := x.input[owner+"/"+repo].Activity
+	if activity == nil || activity.LastRelease == nil {
+		return nil, nil
+	}
+	release := activity.LastRelease
+	return &github.RepositoryRelease{
+		TagName:     github.String(release.TagName),
+		Name:        github.String(release.Name),
+		HTMLURL:     github.String(release.HTMLURL),
+		PublishedAt: &github.Timestamp{Time: release.PublishedAt},
+	}, nil
+}
+
+func (x *loaderClient) GetReleases(ctx *types.Context, owner, repo string, limit int) ([]*model.Release, error) {
+	var releases []*model.Release
+	for _, release := range x.input[owner+"/"+repo].Releases {
+		releases = append(releases, &release.Release)
+	}
+	if len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases, nil
+}
+
+func (x *loaderClient) GetTagProtections(ctx *types.Context, owner, repo string) ([]*model.TagProtection, error) {
+	if protection := x.input[owner+"/"+repo].TagProtection; protection != nil {
+		return protection.Legacy, nil
+	}
+	return nil, nil
+}
+
+// GetRepoRulesets restores only tag rulesets because branch rulesets are not dumped
+func (x *loaderClient) GetRepoRulesets(ctx *types.Context, owner, repo, target string) ([]*model.Ruleset, error) {
+	protection := x.input[owner+"/"+repo].TagProtection
+	if protection == nil || protection.Rulesets == nil {
+		return nil, nil
+	}
+	rulesets := []*model.Ruleset{}
+	for _, ruleset := range protection.Rulesets {
+		if ruleset.Target == target {
+			rulesets = append(rulesets, ruleset)
+		}
+	}
+	return rulesets, nil
+}
+
+func loadAlerts(alerts *model.RegoInputAlerts) []*model.SecurityAlert {
+	if alerts == nil {
+		return nil
+	}
+	if alerts.Alerts == nil {
+		return []*model.SecurityAlert{}
+	}
+	return alerts.Alerts
+}
+
+func (x *loaderClient) GetDependabotAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error) {
+	if security := x.input[owner+"/"+repo].Security; security != nil {
+		return loadAlerts(security.Dependabot), nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetCodeScanningAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error) {
+	if security := x.input[owner+"/"+repo].Security; security != nil {
+		return loadAlerts(security.CodeScanning), nil
+	}
+	return nil, nil
+}
+
+func (x *loaderClient) GetSecretScanningAlerts(ctx *types.Context, owner, repo string) ([]*model.SecurityAlert, error) {
+	if security := x.input[owner+"/"+repo].Security; security != nil {
+		return loadAlerts(security.SecretScanning), nil
+	}
+	return nil, nil
+}
+
+// GetSBOM restores SBOM that has only packages in input.dependencies
+func (x *loaderClient) GetSBOM(ctx *types.Context, owner, repo string) (*model.SBOM, error) {
+	deps := x.input[owner+"/"+repo].Dependencies
+	if deps == nil {
+		return nil, nil
+	}
+	sbom := &model.SBOM{Packages: []*model.SBOMPackage{}}
+	for _, pkg := range deps.Packages {
+		sbom.Packages = append(sbom.Packages, pkg.SBOMPackage())
+	}
+	return sbom, nil
+}
+
+func (x *loaderClient) GetFile(ctx *types.Context, owner, repo, path string) (*github.RepositoryContent, error) {
+	input := x.input[owner+"/"+repo]
+
+	content := &github.RepositoryContent{
+		Type: github.String("file"),
+		Path: github.String(path),
+	}
+	file, ok := input.Files[path]
+	if ok && file.Exists {
+		content.SHA = github.String(file.SHA)
+		content.Size = github.Int(file.Size)
+		content.Content = file.Content
+	}
+
+	// CODEOWNERS and ghaudit.yml files are retrieved regardless of --file option
+	if co := input.CodeOwners; co != nil && co.Path == path {
+		content.Content = github.String(co.Content)
+		return content, nil
+	}
+	if cfg := input.RepoConfig; cfg != nil && cfg.Path == path {
+		content.Content = github.String(cfg.Content)
+		return content, nil
+	}
+
+	if !ok || !file.Exists {
+		return nil, nil
+	}
+	return content, nil
 }
@@ -0,0 +1,307 @@
+package githubapp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-mizutani/goerr"
+
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+)
+
+// RateLimitTransport is http.RoundTripper to handle rate limits of GitHub API.
+//   - Primary rate limit: It tracks X-RateLimit-* headers for each resource (core, graphql, etc.) and waits until reset when the budget is exhausted. go-github aborts a request without sending it if the last response had no remaining budget, so the transport also waits before returning such response.
+//   - Secondary rate limit: It retries 403 and 429 responses after Retry-After seconds, or with backoff if the header is not available.
+//   - Server error: It retries 500, 502, 503 and 504 responses with exponential backoff and jitter.
+//
+// If AdaptiveLimiter is set, each attempt of request waits for a slot of the limiter and results are fed back to it.
+type RateLimitTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	retryDelay time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+	limiter    *AdaptiveLimiter
+
+	mutex  sync.Mutex
+	limits map[string]*rateLimit
+}
+
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+type TransportOption func(x *RateLimitTransport)
+
+// WithMaxRetries sets maximum number of retries of a request. Default is 5.
+func WithMaxRetries(n int) TransportOption {
+	return func(x *RateLimitTransport) {
+		x.maxRetries = n
+	}
+}
+
+// WithRetryDelay sets base delay of exponential backoff. Default is 1 second.
+func WithRetryDelay(d time.Duration) TransportOption {
+	return func(x *RateLimitTransport) {
+		x.retryDelay = d
+	}
+}
+
+// WithSleep replaces function to wait for retry and rate limit reset. It is mainly for testing.
+func WithSleep(sleep func(ctx context.Context, d time.Duration) error) TransportOption {
+	return func(x *RateLimitTransport) {
+		x.sleep = sleep
+	}
+}
+
+// WithLimiter limits concurrent requests by the limiter. The limiter can be shared with multiple transports.
+func WithLimiter(limiter *AdaptiveLimiter) TransportOption {
+	return func(x *RateLimitTransport) {
+		x.limiter = limiter
+	}
+}
+
+func NewRateLimitTransport(base http.RoundTripper, options ...TransportOption) *RateLimitTransport {
+	x := &RateLimitTransport{
+		base:       base,
+		maxRetries: 5,
+		retryDelay: time.Second,
+		sleep:      sleepWithContext,
+		limits:     make(map[string]*rateLimit),
+	}
+	for _, opt := range options {
+		opt(x)
+	}
+	return x
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return goerr.Wrap(ctx.Err())
+	}
+}
+
+// secondaryRateLimitDelay is minimum delay for secondary rate limit without Retry-After header as GitHub recommends
+const secondaryRateLimitDelay = time.Minute
+
+func (x *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	resource := requestResource(req)
+
+	for attempt := 0; ; attempt++ {
+		if err := x.waitReset(ctx, resource); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 {
+			cloned, err := rewindRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			r = cloned
+		}
+
+		if x.limiter != nil {
+			if err := x.limiter.Acquire(ctx); err != nil {
+				return nil, err
+			}
+		}
+		startedAt := time.Now()
+		resp, err := x.base.RoundTrip(r)
+		if err != nil {
+			x.release(time.Since(startedAt), FeedbackServerError)
+			return nil, err
+		}
+		resource = x.update(resp, resource)
+
+		delay, retry := x.retryDelayOf(resp, attempt)
+		x.release(time.Since(startedAt), x.feedback(resp, resource, retry))
+		if !retry || attempt >= x.maxRetries || (req.Body != nil && req.GetBody == nil) {
+			// wait here if the budget is exhausted. Otherwise go-github aborts next request
+			if err := x.waitReset(ctx, resource); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return resp, nil
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		utils.Logger.With("url", req.URL.String()).
+			With("code", resp.StatusCode).
+			With("attempt", attempt+1).
+			With("delay", delay.String()).
+			Warn("retrying GitHub API request")
+		if delay > 0 {
+			if err := x.sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (x *RateLimitTransport) release(latency time.Duration, feedback Feedback) {
+	if x.limiter != nil {
+		x.limiter.Release(latency, feedback)
+	}
+}
+
+// feedback classifies response for AdaptiveLimiter. retry is result of retryDelayOf.
+func (x *RateLimitTransport) feedback(resp *http.Response, resource string, retry bool) Feedback {
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return FeedbackServerError
+	case resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return FeedbackBudgetLow
+	case retry && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests):
+		return FeedbackThrottled
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	// less than 10% of budget remains
+	if limit, ok := x.limits[resource]; ok && limit.remaining*10 < limit.limit {
+		return FeedbackBudgetLow
+	}
+	return FeedbackSuccess
+}
+
+// requestResource returns rate limit resource of request before the response tells actual one.
+func requestResource(req *http.Request) string {
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		return "graphql"
+	}
+	return "core"
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	cloned := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		cloned.Body = body
+	}
+	return cloned, nil
+}
+
+// update stores rate limit of response and returns resource name of the response.
+func (x *RateLimitTransport) update(resp *http.Response, resource string) string {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return resource
+	}
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if name := resp.Header.Get("X-RateLimit-Resource"); name != "" {
+		resource = name
+	}
+
+	x.mutex.Lock()
+	x.limits[resource] = &rateLimit{
+		limit:     limit,
+		remaining: remaining,
+		reset:     time.Unix(reset, 0),
+	}
+	x.mutex.Unlock()
+
+	if remaining%100 == 0 {
+		utils.Logger.With("resource", resource).
+			With("remaining", remaining).
+			With("limit", limit).
+			With("reset", time.Unix(reset, 0)).
+			Debug("GitHub API rate limit budget")
+	}
+
+	return resource
+}
+
+// waitReset waits until reset of rate limit if the budget of resource is exhausted.
+func (x *RateLimitTransport) waitReset(ctx context.Context, resource string) error {
+	x.mutex.Lock()
+	limit, ok := x.limits[resource]
+	var delay time.Duration
+	if ok && limit.remaining <= 0 {
+		// 1 second margin for clock skew
+		delay = time.Until(limit.reset) + time.Second
+	}
+	x.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	utils.Logger.With("resource", resource).
+		With("reset", limit.reset).
+		Warn("GitHub API rate limit exceeded, waiting until reset")
+	return x.sleep(ctx, delay)
+}
+
+// retryDelayOf returns delay before retry and true if the response should be retried.
+func (x *RateLimitTransport) retryDelayOf(resp *http.Response, attempt int) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(sec) * time.Second, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			// primary rate limit. waitReset before next attempt waits until reset
+			return 0, true
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || isSecondaryRateLimit(resp) {
+			return maxDuration(secondaryRateLimitDelay, x.backoff(attempt)), true
+		}
+		return 0, false
+
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return x.backoff(attempt), true
+
+	default:
+		return 0, false
+	}
+}
+
+// isSecondaryRateLimit checks body of 403 response. The body is restored to be read again.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
+
+// backoff returns exponential delay with jitter up to retryDelay.
+func (x *RateLimitTransport) backoff(attempt int) time.Duration {
+	delay := x.retryDelay << attempt
+	if x.retryDelay > 0 {
+		// #nosec G404: jitter does not require cryptographic randomness
+		delay += time.Duration(rand.Int63n(int64(x.retryDelay)))
+	}
+	return delay
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
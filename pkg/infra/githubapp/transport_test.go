@@ -0,0 +1,224 @@
+package githubapp_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sleepRecorder struct {
+	mutex  sync.Mutex
+	delays []time.Duration
+}
+
+func (x *sleepRecorder) sleep(ctx context.Context, d time.Duration) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.delays = append(x.delays, d)
+	return nil
+}
+
+// newTestServer responds with handlers in order. The last handler is used for remaining requests.
+func newTestServer(t *testing.T, handlers ...http.HandlerFunc) (*httptest.Server, *int) {
+	var count int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idx := count
+		if idx >= len(handlers) {
+			idx = len(handlers) - 1
+		}
+		count++
+		handlers[idx](w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &count
+}
+
+func respond(code int, headers map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"message":"x"}`))
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	newClient := func(recorder *sleepRecorder, options ...githubapp.TransportOption) *http.Client {
+		options = append([]githubapp.TransportOption{
+			githubapp.WithSleep(recorder.sleep),
+			githubapp.WithRetryDelay(time.Second),
+		}, options...)
+		return &http.Client{Transport: githubapp.NewRateLimitTransport(http.DefaultTransport, options...)}
+	}
+
+	t.Run("retry server error with backoff", func(t *testing.T) {
+		srv, count := newTestServer(t,
+			respond(http.StatusBadGateway, nil),
+			respond(http.StatusServiceUnavailable, nil),
+			respond(http.StatusOK, nil),
+		)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 3, *count)
+		require.Len(t, recorder.delays, 2)
+		assert.GreaterOrEqual(t, recorder.delays[0], time.Second)
+		assert.Less(t, recorder.delays[0], 2*time.Second)
+		assert.GreaterOrEqual(t, recorder.delays[1], 2*time.Second)
+		assert.Less(t, recorder.delays[1], 3*time.Second)
+	})
+
+	t.Run("give up after max retries", func(t *testing.T) {
+		srv, count := newTestServer(t, respond(http.StatusInternalServerError, nil))
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder, githubapp.WithMaxRetries(2)).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, 3, *count)
+	})
+
+	t.Run("honor Retry-After of secondary rate limit", func(t *testing.T) {
+		srv, count := newTestServer(t,
+			respond(http.StatusForbidden, map[string]string{"Retry-After": "7"}),
+			respond(http.StatusOK, nil),
+		)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, *count)
+		assert.Equal(t, []time.Duration{7 * time.Second}, recorder.delays)
+	})
+
+	t.Run("wait at least one minute for secondary rate limit without Retry-After", func(t *testing.T) {
+		srv, _ := newTestServer(t,
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit"}`))
+			},
+			respond(http.StatusOK, nil),
+		)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Len(t, recorder.delays, 1)
+		assert.GreaterOrEqual(t, recorder.delays[0], time.Minute)
+	})
+
+	t.Run("do not retry forbidden without rate limit", func(t *testing.T) {
+		srv, count := newTestServer(t, respond(http.StatusForbidden, nil))
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, 1, *count)
+		assert.Empty(t, recorder.delays)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"message":"x"}`, string(body))
+	})
+
+	t.Run("wait until reset when budget is exhausted", func(t *testing.T) {
+		reset := strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)
+		srv, count := newTestServer(t,
+			respond(http.StatusOK, map[string]string{
+				"X-RateLimit-Limit":     "5000",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     reset,
+			}),
+		)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 1, *count)
+		require.Len(t, recorder.delays, 1)
+		assert.InDelta(t, float64(31*time.Second), float64(recorder.delays[0]), float64(2*time.Second))
+	})
+
+	t.Run("retry primary rate limit after reset", func(t *testing.T) {
+		reset := strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10)
+		srv, count := newTestServer(t,
+			respond(http.StatusForbidden, map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     reset,
+			}),
+			respond(http.StatusOK, map[string]string{
+				"X-RateLimit-Remaining": "4999",
+				"X-RateLimit-Reset":     reset,
+			}),
+		)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, *count)
+		require.Len(t, recorder.delays, 1)
+		assert.InDelta(t, float64(11*time.Second), float64(recorder.delays[0]), float64(2*time.Second))
+	})
+
+	t.Run("send request body again on retry", func(t *testing.T) {
+		var bodies []string
+		srv, _ := newTestServer(t,
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+			},
+		)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder).Post(srv.URL, "application/json", strings.NewReader(`{"query":"x"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{`{"query":"x"}`, `{"query":"x"}`}, bodies)
+	})
+
+	t.Run("feed back secondary rate limit to limiter", func(t *testing.T) {
+		srv, _ := newTestServer(t,
+			respond(http.StatusTooManyRequests, map[string]string{"Retry-After": "1"}),
+			respond(http.StatusOK, nil),
+		)
+		limiter := githubapp.NewAdaptiveLimiter(8, 8)
+		recorder := &sleepRecorder{}
+		resp, err := newClient(recorder, githubapp.WithLimiter(limiter)).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 4, limiter.Limit())
+	})
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/opac"
+)
+
+// auditAggregate evaluates all repository data at once with aggregate policy for rules across repositories. It does nothing if aggregate policy is not configured.
+func (x *Usecase) auditAggregate(ctx *types.Context, owner string, orgInput *model.RegoOrgInput, inputs []*model.RegoInput) ([]*auditRecord, error) {
+	if x.clients.AggregatePolicy() == nil {
+		return nil, nil
+	}
+
+	aggregateInput := &model.RegoAggregateInput{
+		Org:       orgInput,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+	repoMap := make(map[string]*model.RegoInput)
+	for _, input := range inputs {
+		// organization data is provided as aggregateInput.Org
+		repo := *input
+		repo.Org = nil
+		aggregateInput.Repos = append(aggregateInput.Repos, &repo)
+		repoMap[input.Repo.GetFullName()] = input
+	}
+
+	utils.Logger.With("repos", len(inputs)).Trace("evaluating aggregate data")
+	var output model.RegoAggregateOutput
+	if err := x.clients.AggregatePolicy().Query(ctx, aggregateInput, &output); err != nil {
+		if errors.Is(err, opac.ErrNoEvalResult) {
+			utils.Logger.Debug("no aggregate policy")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("owner", owner)
+	}
+
+	org := &github.Organization{Login: github.String(owner)}
+	if orgInput != nil {
+		org = orgInput.Org
+	}
+
+	var results []*auditRecord
+	for _, fail := range output.Fail {
+		record := &auditRecord{
+			RegoFail: fail.RegoFail,
+		}
+		if input, ok := repoMap[fail.Repo]; ok {
+			if exempted(input, &fail.RegoFail) {
+				continue
+			}
+			record.Repo = input.Repo
+		} else {
+			if fail.Repo != "" {
+				utils.Logger.With("repo", fail.Repo).Warn("repository in aggregate policy result is not found")
+			}
+			record.Org = org
+		}
+		results = append(results, record)
+	}
+
+	return results, nil
+}
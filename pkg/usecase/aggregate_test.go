@@ -0,0 +1,104 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra"
+	"github.com/m-mizutani/ghaudit/pkg/usecase"
+	"github.com/m-mizutani/opac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAggregatePolicy = `package github.aggregate
+
+fail[res] {
+	r := input.repos[_]
+	other := input.repos[_]
+	r.repo.name != other.repo.name
+	r.repo.description == other.repo.description
+	res := {
+		"category": "description must be unique",
+		"message": sprintf("same as %s", [other.repo.name]),
+		"repo": r.repo.full_name,
+	}
+}
+
+fail[res] {
+	count(input.repos) > 2
+	res := {
+		"category": "too many repositories",
+		"message": sprintf("%s has %d repositories", [input.org.org.login, count(input.repos)]),
+		"repo": "",
+	}
+}
+
+fail[res] {
+	res := {
+		"category": "unknown repository",
+		"message": "ghost",
+		"repo": "my-org/ghost",
+	}
+}
+
+fail[res] {
+	r := input.repos[_]
+	r.org
+	res := {
+		"category": "organization data must not be embedded",
+		"message": "",
+		"repo": r.repo.full_name,
+	}
+}
+`
+
+func TestAuditAggregate(t *testing.T) {
+	now := time.Now().UTC()
+	newInput := func(name, description string) *model.RegoInput {
+		repo := newTestRepo(name, now)
+		repo.Description = github.String(description)
+		return &model.RegoInput{Repo: repo}
+	}
+
+	blue := newInput("blue", "shared")
+	red := newInput("red", "shared")
+	red.RepoConfig = model.ParseRepoConfig(model.RepoConfigPath, `exemptions:
+  - category: description must be unique
+    reason: fork of blue
+`, now)
+	green := newInput("green", "unique")
+	org := &model.RegoOrgInput{
+		Org: &github.Organization{
+			Login:   github.String("my-org"),
+			HTMLURL: github.String("https://github.com/my-org"),
+		},
+	}
+
+	aggregatePolicy, err := opac.NewLocal(
+		opac.WithPolicyData("aggregate.rego", testAggregatePolicy),
+		opac.WithPackage("github.aggregate"),
+	)
+	require.NoError(t, err)
+	_, policy := newInputRecorder(nil)
+	slack := &slackRecorder{}
+
+	uc := usecase.New(infra.New(
+		infra.WithGitHubApp(newTestLoader(t, org, blue, red, green)),
+		infra.WithPolicy(policy),
+		infra.WithAggregatePolicy(aggregatePolicy),
+		infra.WithSlack(slack),
+	))
+	require.ErrorIs(t, uc.Audit(types.NewContext(), "my-org"), types.ErrViolationDetected)
+
+	assert.Equal(t, map[string][]string{
+		// violation of red is exempted by its ghaudit.yml
+		"description must be unique": {"<https://github.com/my-org/blue|my-org/blue>: same as red"},
+		"too many repositories":      {"<https://github.com/my-org|my-org>: my-org has 3 repositories"},
+		// violation of repository not in the audit is reported as organization one
+		"unknown repository": {"<https://github.com/my-org|my-org>: ghost"},
+	}, slack.violations)
+}
@@ -16,61 +16,426 @@ import (
 	"github.com/m-mizutani/goerr"
 )
 
+// auditRecord is a detected violation. Either one of Repo or Org is set.
 type auditRecord struct {
 	model.RegoFail
 	Repo      *github.Repository
+	Org       *github.Organization
 	ScannedAt time.Time
 }
 
-func createRegoInput(ctx *types.Context, client githubapp.Client, repo *github.Repository) (*model.RegoInput, error) {
-	now := time.Now().UTC()
-	repoName := repo.GetName()
-	ownerName := repo.Owner.GetLogin()
-
-	utils.Logger.With("repo", repoName).Trace("retrieving repository data")
+func (x *auditRecord) targetName() string {
+	if x.Repo != nil {
+		return x.Repo.GetFullName()
+	}
+	return x.Org.GetLogin()
+}
 
-	githubBranches, err := client.GetBranches(ctx, ownerName, repoName)
-	if err != nil {
-		return nil, goerr.Wrap(err)
+func (x *auditRecord) targetURL() string {
+	if x.Repo != nil {
+		return x.Repo.GetHTMLURL()
 	}
+	return x.Org.GetHTMLURL()
+}
 
-	var branches []*model.RegoInputBranch
-	for _, branch := range githubBranches {
-		b := &model.RegoInputBranch{
-			Branch: *branch,
+func (x *Usecase) getFiles(ctx *types.Context, client githubapp.Client, owner, repo string) (map[string]*model.RegoInputFile, error) {
+	files := make(map[string]*model.RegoInputFile)
+	for _, path := range x.files {
+		content, err := client.GetFile(ctx, owner, repo, path)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		if content == nil {
+			files[path] = &model.RegoInputFile{Exists: false}
+			continue
 		}
-		if branch.GetProtected() {
-			protection, err := client.GetBranchProtection(ctx, ownerName, repoName, branch.GetName())
+
+		file := &model.RegoInputFile{
+			Exists: true,
+			Size:   content.GetSize(),
+			SHA:    content.GetSHA(),
+		}
+		if x.fileContent {
+			decoded, err := content.GetContent()
 			if err != nil {
-				return nil, goerr.Wrap(err)
+				return nil, goerr.Wrap(err).With("path", path)
 			}
-			b.Protection = protection
+			file.Content = &decoded
+		}
+		files[path] = file
+	}
+
+	return files, nil
+}
+
+// attachTeamMembers adds members of teams retrieved with organization data. Members are empty if organization data is not available.
+func attachTeamMembers(teams []*github.Team, org *model.RegoOrgInput) []*model.RegoInputTeam {
+	orgTeams := make(map[string]*model.RegoInputTeam)
+	if org != nil {
+		for _, team := range org.Teams {
+			orgTeams[team.GetSlug()] = team
+		}
+	}
+
+	var resp []*model.RegoInputTeam
+	for _, team := range teams {
+		t := &model.RegoInputTeam{Team: *team}
+		if orgTeam, ok := orgTeams[team.GetSlug()]; ok {
+			t.Members = orgTeam.Members
+			t.Maintainers = orgTeam.Maintainers
+		}
+		resp = append(resp, t)
+	}
+	return resp
+}
+
+// repoOrg returns organization data to be embedded in repository data. It is omitted unless policy refers input.org because it is same for all repositories and can be large.
+func repoOrg(org *model.RegoOrgInput, fields model.InputFields) *model.RegoOrgInput {
+	if !fields.Has("org") {
+		return nil
+	}
+	return org
+}
+
+// orgTeams returns all teams of the organization. It returns nil if organization data is not available.
+func orgTeams(org *model.RegoOrgInput) []*model.RegoInputTeam {
+	if org == nil {
+		return nil
+	}
+	return org.Teams
+}
+
+// repoApps returns app installations that can access all repositories. Repositories of installation with selected repositories can not be retrieved by an installation token.
+func repoApps(org *model.RegoOrgInput) []*model.AppInstallation {
+	if org == nil {
+		return nil
+	}
+
+	var apps []*model.AppInstallation
+	for _, installation := range org.Installations {
+		if installation.RepositorySelection == "all" {
+			apps = append(apps, installation)
+		}
+	}
+	return apps
+}
+
+func getHooks(ctx *types.Context, client githubapp.Client, owner, repo string) ([]*model.RegoInputHook, error) {
+	githubHooks, err := client.GetHooks(ctx, owner, repo)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var hooks []*model.RegoInputHook
+	for _, hook := range githubHooks {
+		deliveries, err := client.GetHookDeliveries(ctx, owner, repo, hook.GetID())
+		if err != nil {
+			return nil, goerr.Wrap(err)
 		}
-		branches = append(branches, b)
+		hooks = append(hooks, model.NewRegoInputHook(hook, deliveries))
+	}
+	return hooks, nil
+}
+
+func getActivity(ctx *types.Context, client githubapp.Client, repo *github.Repository, commits []*github.RepositoryCommit, now time.Time) (*model.RegoInputActivity, error) {
+	ownerName, repoName := repo.GetOwner().GetLogin(), repo.GetName()
+
+	pulls, err := client.GetOpenPullRequests(ctx, ownerName, repoName)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+	release, err := client.GetLatestRelease(ctx, ownerName, repoName)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	return model.NewRegoInputActivity(commits, pulls, release, now), nil
+}
+
+func getTagProtection(ctx *types.Context, client githubapp.Client, owner, repo string) (*model.RegoInputTagProtection, error) {
+	legacy, err := client.GetTagProtections(ctx, owner, repo)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+	rulesets, err := client.GetRepoRulesets(ctx, owner, repo, "tag")
+	if err != nil {
+		return nil, goerr.Wrap(err)
 	}
 
-	collaborators, err := client.GetCollaborators(ctx, ownerName, repoName)
+	return model.NewRegoInputTagProtection(legacy, rulesets), nil
+}
+
+// releaseLimit is number of recent releases to be retrieved into input.releases
+const releaseLimit = 10
+
+func getReleases(ctx *types.Context, client githubapp.Client, owner, repo string) ([]*model.RegoInputRelease, error) {
+	githubReleases, err := client.GetReleases(ctx, owner, repo, releaseLimit)
 	if err != nil {
 		return nil, goerr.Wrap(err)
 	}
 
-	hooks, err := client.GetHooks(ctx, ownerName, repoName)
+	var releases []*model.RegoInputRelease
+	for _, release := range githubReleases {
+		releases = append(releases, model.NewRegoInputRelease(release))
+	}
+	return releases, nil
+}
+
+// repoProperties returns custom property values of repo. It returns nil if custom properties are not available.
+func repoProperties(repo *github.Repository, org *model.RegoOrgInput) map[string]interface{} {
+	if org == nil || org.PropertySchema == nil {
+		return nil
+	}
+	return model.NewRegoInputProperties(org.PropertySchema, org.PropertyValues[repo.GetFullName()])
+}
+
+// getRepoConfig retrieves per-repository configuration file. It returns nil if the file does not exist.
+func getRepoConfig(ctx *types.Context, client githubapp.Client, owner, repo string, now time.Time) (*model.RepoConfig, error) {
+	file, err := client.GetFile(ctx, owner, repo, model.RepoConfigPath)
 	if err != nil {
 		return nil, goerr.Wrap(err)
 	}
+	if file == nil {
+		return nil, nil
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, goerr.Wrap(err).With("path", model.RepoConfigPath)
+	}
+	return model.ParseRepoConfig(model.RepoConfigPath, content, now), nil
+}
+
+func getCodeOwners(ctx *types.Context, client githubapp.Client, owner, repo string) (*model.CodeOwners, error) {
+	for _, path := range model.CodeOwnersPaths {
+		file, err := client.GetFile(ctx, owner, repo, path)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		if file == nil {
+			continue
+		}
+
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, goerr.Wrap(err).With("path", path)
+		}
+		return model.ParseCodeOwners(path, content), nil
+	}
+
+	return nil, nil
+}
 
-	teams, err := client.GetTeams(ctx, ownerName, repoName)
+// getBranches retrieves branches and protections of protected branches. Protections are retrieved concurrently, and number of requests in flight is limited by GitHub API client shared with other repositories.
+func (x *Usecase) getBranches(ctx *types.Context, client githubapp.Client, owner, repo string) ([]*model.RegoInputBranch, error) {
+	githubBranches, err := client.GetBranches(ctx, owner, repo)
 	if err != nil {
 		return nil, goerr.Wrap(err)
 	}
+	if len(githubBranches) == 0 {
+		return nil, nil
+	}
+
+	branches := make([]*model.RegoInputBranch, len(githubBranches))
+	errs := make([]error, len(githubBranches))
+	var wg sync.WaitGroup
+
+	for i, branch := range githubBranches {
+		branches[i] = &model.RegoInputBranch{
+			Branch: *branch,
+		}
+		if !branch.GetProtected() {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			protection, err := client.GetBranchProtection(ctx, owner, repo, name)
+			if err != nil {
+				errs[i] = goerr.Wrap(err).With("branch", name)
+				return
+			}
+			branches[i].Protection = protection
+		}(i, branch.GetName())
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return branches, nil
+}
+
+// createRegoInput retrieves repository data. If reused (repository data of previous audit) is given, data depending only on repository content (repo_config, dependencies, files and codeowners) is taken from it instead of retrieval. Other data is always retrieved because it can be changed without push.
+func (x *Usecase) createRegoInput(ctx *types.Context, client githubapp.Client, repo *github.Repository, org *model.RegoOrgInput, reused *model.RegoInput) (*model.RegoInput, error) {
+	now := time.Now().UTC()
+	repoName := repo.GetName()
+	ownerName := repo.Owner.GetLogin()
+	fields := x.inputFields
 
+	utils.Logger.With("repo", repoName).Trace("retrieving repository data")
+
+	// repo, apps, properties, org and timestamp do not require API calls. repo_config is always required for exemptions
 	input := &model.RegoInput{
-		Repo:          repo,
-		Branches:      branches,
-		Collaborators: collaborators,
-		Hooks:         hooks,
-		Teams:         teams,
-		Timestamp:     now.Unix(),
+		Repo:       repo,
+		Apps:       repoApps(org),
+		Properties: repoProperties(repo, org),
+		Org:        repoOrg(org, fields),
+		Timestamp:  now.Unix(),
+	}
+
+	var err error
+	if fields.Has("branches") {
+		if input.Branches, err = x.getBranches(ctx, client, ownerName, repoName); err != nil {
+			return nil, err
+		}
+	}
+
+	// collaborators and teams are also used to build access and validate CODEOWNERS
+	var collaborators, directCollaborators []*github.User
+	var teams []*github.Team
+	if fields.HasAny("collaborators", "access", "codeowners") {
+		collaborators, err = client.GetCollaborators(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		input.Collaborators = collaborators
+	}
+	if fields.HasAny("teams", "access", "codeowners") {
+		teams, err = client.GetTeams(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		input.Teams = attachTeamMembers(teams, org)
+	}
+
+	if fields.Has("access") {
+		directCollaborators, err = client.GetDirectCollaborators(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		input.DirectCollaborators = directCollaborators
+		input.Access = model.BuildAccess(&model.AccessSource{
+			Collaborators:       collaborators,
+			DirectCollaborators: directCollaborators,
+			Teams:               teams,
+			Org:                 org,
+		})
+	}
+
+	if fields.Has("hooks") {
+		if input.Hooks, err = getHooks(ctx, client, ownerName, repoName); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.Has("environments") {
+		githubEnvs, err := client.GetEnvironments(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		for _, env := range githubEnvs {
+			e := &model.RegoInputEnvironment{
+				Environment: *env,
+			}
+			if env.GetDeploymentBranchPolicy().GetCustomBranchPolicies() {
+				policies, err := client.GetDeploymentBranchPolicies(ctx, ownerName, repoName, env.GetName())
+				if err != nil {
+					return nil, goerr.Wrap(err)
+				}
+				e.BranchPolicies = policies
+			}
+
+			rules, err := client.GetDeploymentProtectionRules(ctx, ownerName, repoName, env.GetName())
+			if err != nil {
+				return nil, goerr.Wrap(err)
+			}
+			e.CustomProtectionRules = rules
+
+			input.Environments = append(input.Environments, e)
+		}
+	}
+
+	if fields.Has("security") {
+		analysis, err := client.GetSecurityAndAnalysis(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		dependabotAlerts, err := client.GetDependabotAlerts(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		codeScanningAlerts, err := client.GetCodeScanningAlerts(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		secretScanningAlerts, err := client.GetSecretScanningAlerts(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		input.Security = &model.RegoInputSecurity{
+			Analysis:       analysis,
+			Dependabot:     model.NewRegoInputAlerts(dependabotAlerts),
+			CodeScanning:   model.NewRegoInputAlerts(codeScanningAlerts),
+			SecretScanning: model.NewRegoInputAlerts(secretScanningAlerts),
+		}
+	}
+
+	if fields.Has("runners") {
+		repoRunners, err := client.GetRepoRunners(ctx, ownerName, repoName)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		input.Runners = model.NewRegoInputRunners(repo, repoRunners, org)
+	}
+
+	// recent commits are shared with activity (only the last one) and commit statistics
+	if fields.Has("activity") || (fields.Has("commits") && 0 < x.commits) {
+		commitLimit := 1
+		if 1 < x.commits && fields.Has("commits") {
+			commitLimit = int(x.commits)
+		}
+		commits, err := client.GetCommits(ctx, ownerName, repoName, repo.GetDefaultBranch(), commitLimit)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		if fields.Has("activity") {
+			if input.Activity, err = getActivity(ctx, client, repo, commits, now); err != nil {
+				return nil, err
+			}
+		}
+		if fields.Has("commits") && 0 < x.commits {
+			input.Commits = model.NewRegoInputCommits(commits)
+		}
+	}
+
+	if fields.Has("tag_protection") {
+		if input.TagProtection, err = getTagProtection(ctx, client, ownerName, repoName); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.Has("releases") {
+		if input.Releases, err = getReleases(ctx, client, ownerName, repoName); err != nil {
+			return nil, err
+		}
+	}
+
+	if reused != nil {
+		reuseContentData(input, reused, fields, now)
+	} else if err := x.getContentData(ctx, client, input, now); err != nil {
+		return nil, err
+	}
+
+	// CODEOWNERS is validated with current collaborators and teams
+	if input.CodeOwners != nil {
+		input.CodeOwners.Validate(ownerName, collaborators, teams, orgTeams(org))
 	}
 
 	utils.Logger.With("repo", repoName).Trace("created input")
@@ -78,15 +443,61 @@ func createRegoInput(ctx *types.Context, client githubapp.Client, repo *github.R
 	return input, nil
 }
 
-func (x *Usecase) evaluate(ctx *types.Context, input *model.RegoInput) ([]*auditRecord, error) {
-	if x.dumpDir != "" {
-		path := filepath.Join(x.dumpDir, fmt.Sprintf("%s.json", input.Repo.GetName()))
-		fd, err := os.Create(path)
+// getContentData retrieves data depending only on repository content. repo_config is always required for exemptions.
+func (x *Usecase) getContentData(ctx *types.Context, client githubapp.Client, input *model.RegoInput, now time.Time) error {
+	ownerName, repoName := input.Repo.GetOwner().GetLogin(), input.Repo.GetName()
+	fields := x.inputFields
+
+	repoConfig, err := getRepoConfig(ctx, client, ownerName, repoName, now)
+	if err != nil {
+		return err
+	}
+	input.RepoConfig = repoConfig
+
+	if fields.Has("dependencies") {
+		sbom, err := client.GetSBOM(ctx, ownerName, repoName)
 		if err != nil {
-			return nil, goerr.Wrap(err)
+			return goerr.Wrap(err)
 		}
-		if err := json.NewEncoder(fd).Encode(input); err != nil {
-			return nil, goerr.Wrap(err)
+		input.Dependencies = model.NewRegoInputDependencies(sbom)
+	}
+
+	if fields.Has("files") {
+		if input.Files, err = x.getFiles(ctx, client, ownerName, repoName); err != nil {
+			return err
+		}
+	}
+
+	if fields.Has("codeowners") {
+		if input.CodeOwners, err = getCodeOwners(ctx, client, ownerName, repoName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (x *Usecase) dumpInput(input *model.RegoInput) error {
+	path := filepath.Join(x.dumpDir, fmt.Sprintf("%s.json", input.Repo.GetName()))
+	fd, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	defer fd.Close()
+
+	// organization data is dumped separately
+	dumped := *input
+	dumped.Org = nil
+	if err := json.NewEncoder(fd).Encode(&dumped); err != nil {
+		return goerr.Wrap(err)
+	}
+	return nil
+}
+
+func (x *Usecase) evaluate(ctx *types.Context, input *model.RegoInput) ([]*auditRecord, error) {
+	if x.dumpDir != "" {
+		if err := x.dumpInput(input); err != nil {
+			return nil, err
 		}
 	}
 
@@ -99,15 +510,75 @@ func (x *Usecase) evaluate(ctx *types.Context, input *model.RegoInput) ([]*audit
 	}
 
 	for _, fail := range output.Fail {
+		if exempted(input, fail) {
+			continue
+		}
 		results = append(results, &auditRecord{
 			RegoFail: *fail,
 			Repo:     input.Repo,
 		})
 	}
+	results = append(results, repoConfigRecords(input)...)
 
 	return results, nil
 }
 
+// exempted returns true if fail is exempted by per-repository configuration file.
+func exempted(input *model.RegoInput, fail *model.RegoFail) bool {
+	exemption := input.RepoConfig.Exempt(fail)
+	if exemption == nil {
+		return false
+	}
+
+	utils.Logger.With("repo", input.Repo.GetFullName()).
+		With("category", fail.Category).
+		With("reason", exemption.Reason).
+		Debug("violation is exempted")
+	return true
+}
+
+// repoConfigRecords reports errors of per-repository configuration file including invalid and expired exemptions.
+func repoConfigRecords(input *model.RegoInput) []*auditRecord {
+	if input.RepoConfig == nil {
+		return nil
+	}
+
+	var records []*auditRecord
+	for _, cfgErr := range input.RepoConfig.Errors {
+		records = append(records, &auditRecord{
+			RegoFail: model.RegoFail{
+				Category: model.RepoConfigErrorCategory,
+				Message:  fmt.Sprintf("%s: %s", cfgErr.Kind, cfgErr.Message),
+			},
+			Repo: input.Repo,
+		})
+	}
+	return records
+}
+
+// filterByProperties selects repositories that match all filters. It fails if custom properties are not available because no repository can be selected.
+func filterByProperties(repos []*github.Repository, org *model.RegoOrgInput, filters []*model.PropertyFilter) ([]*github.Repository, error) {
+	if org == nil || org.PropertySchema == nil {
+		return nil, goerr.Wrap(types.ErrInvalidConfig, "custom properties are not available for property filter")
+	}
+
+	var resp []*github.Repository
+	for _, repo := range repos {
+		props := repoProperties(repo, org)
+		matched := true
+		for _, filter := range filters {
+			if !filter.Match(props) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			resp = append(resp, repo)
+		}
+	}
+	return resp, nil
+}
+
 func (x *Usecase) Audit(ctx *types.Context, owner string) error {
 	startedAt := time.Now()
 
@@ -135,6 +606,33 @@ func (x *Usecase) Audit(ctx *types.Context, owner string) error {
 		utils.Logger.With("total repos", len(repos)).Trace("filtered by skip-archived option")
 	}
 
+	orgFields := x.orgInputFields()
+	orgInput, err := createRegoOrgInput(ctx, x.clients.GitHubApp(), owner, orgFields)
+	if err != nil {
+		return err
+	}
+	if orgInput == nil {
+		if orgFields.Any() {
+			utils.Logger.With("org", owner).Warn("organization data is not available")
+		}
+	} else {
+		utils.Logger.With("org", orgInput.Org.GetLogin()).Info("retrieved organization data")
+		if x.dumpDir != "" {
+			if err := x.dumpOrgInput(orgInput); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(x.propertyFilters) > 0 {
+		filtered, err := filterByProperties(repos, orgInput, x.propertyFilters)
+		if err != nil {
+			return err
+		}
+		repos = filtered
+		utils.Logger.With("total repos", len(repos)).Trace("filtered by custom properties")
+	}
+
 	limit := len(repos)
 	if 0 < x.limit && int(x.limit) < limit {
 		limit = int(x.limit)
@@ -142,22 +640,80 @@ func (x *Usecase) Audit(ctx *types.Context, owner string) error {
 
 	result := newAuditResult(repos, startedAt)
 
+	inc, err := x.newIncremental(owner, startedAt.UTC())
+	if err != nil {
+		return err
+	}
+	cp, err := x.newCheckpoint(owner, startedAt)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		// report of resumed audit covers from the beginning of interrupted one
+		result.StartedAt = cp.meta.StartedAt
+	}
+
+	orgRecords, err := x.auditOrg(ctx, orgInput)
+	if err != nil {
+		return err
+	}
+	result.Add(orgRecords...)
+
+	// repositories completed before interruption are not retrieved and evaluated again
+	var inputs []*model.RegoInput
+	var pending []*github.Repository
+	for _, repo := range repos[:limit] {
+		entry := cp.load(repo)
+		if entry == nil {
+			pending = append(pending, repo)
+			continue
+		}
+		// repository data in checkpoint may be older than current one
+		entry.Input.Repo = repo
+		entry.Input.Org = repoOrg(orgInput, x.inputFields)
+		if err := inc.save(entry.Input, time.Unix(entry.Input.Timestamp, 0).UTC()); err != nil {
+			return err
+		}
+		if x.dumpDir != "" {
+			if err := x.dumpInput(entry.Input); err != nil {
+				return err
+			}
+		}
+		result.Add(entry.records(repo)...)
+		inputs = append(inputs, entry.Input)
+	}
+	if cp != nil {
+		utils.Logger.With("completed", len(inputs)).With("pending", len(pending)).Info("loaded checkpoint")
+	}
+
 	errCh := make(chan error)
-	inputCh := make(chan *model.RegoInput, limit)
-	repoCh := make(chan *github.Repository, limit)
+	inputCh := make(chan *model.RegoInput, len(pending))
+	repoCh := make(chan *github.Repository, len(pending))
 
 	var wg sync.WaitGroup
 
-	for i := 0; i < int(x.thread); i++ {
+	for i := 0; i < int(x.concurrency); i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for repo := range repoCh {
-				input, err := createRegoInput(ctx, x.clients.GitHubApp(), repo)
+				now := time.Now().UTC()
+				reused := inc.load(repo)
+				if reused != nil {
+					utils.Logger.With("repo", repo.GetFullName()).Debug("reuse repo content data of previous audit")
+				}
+
+				input, err := x.createRegoInput(ctx, x.clients.GitHubApp(), repo, orgInput, reused)
 				if err != nil {
 					errCh <- err
 					return
 				}
+				if reused == nil {
+					if err := inc.save(input, now); err != nil {
+						errCh <- err
+						return
+					}
+				}
 				inputCh <- input
 			}
 		}()
@@ -167,11 +723,11 @@ func (x *Usecase) Audit(ctx *types.Context, owner string) error {
 		close(inputCh)
 	}()
 
-	for i := 0; i < limit; i++ {
-		repoCh <- repos[i]
+	for _, repo := range pending {
+		repoCh <- repo
 	}
 	close(repoCh)
-	utils.Logger.With("limit", limit).Trace("pushed repos")
+	utils.Logger.With("limit", limit).With("pending", len(pending)).Trace("pushed repos")
 
 Loop:
 	for {
@@ -185,7 +741,11 @@ Loop:
 			if err != nil {
 				return err
 			}
+			if err := cp.save(input, records); err != nil {
+				return err
+			}
 			result.Add(records...)
+			inputs = append(inputs, input)
 
 		case err := <-errCh:
 			if err != nil {
@@ -194,6 +754,20 @@ Loop:
 		}
 	}
 
+	aggregateRecords, err := x.auditAggregate(ctx, owner, orgInput, inputs)
+	if err != nil {
+		return err
+	}
+	result.Add(aggregateRecords...)
+
+	// save state before output because output fails if violation is detected
+	if err := inc.commit(); err != nil {
+		return err
+	}
+	if err := cp.complete(time.Now()); err != nil {
+		return err
+	}
+
 	result.CompletedAt = time.Now()
 	if err := x.output(ctx, result); err != nil {
 		return err
@@ -0,0 +1,238 @@
+package usecase_test
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra"
+	"github.com/m-mizutani/ghaudit/pkg/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditPropertyFilter(t *testing.T) {
+	now := time.Now().UTC()
+	blue := &model.RegoInput{
+		Repo:       newTestRepo("blue", now),
+		Properties: map[string]interface{}{"env": "prod", "tags": []string{"pci", "web"}},
+	}
+	red := &model.RegoInput{
+		Repo:       newTestRepo("red", now),
+		Properties: map[string]interface{}{"env": "dev", "tags": []string{"web"}},
+	}
+	// green has no property value and default value is applied
+	green := &model.RegoInput{Repo: newTestRepo("green", now)}
+
+	org := &model.RegoOrgInput{
+		Org: &github.Organization{Login: github.String("my-org")},
+		PropertySchema: []*model.CustomProperty{
+			{PropertyName: "env", ValueType: "single_select", DefaultValue: "dev"},
+			{PropertyName: "tags", ValueType: "multi_select"},
+		},
+	}
+
+	audit := func(t *testing.T, org *model.RegoOrgInput, filters ...string) ([]string, error) {
+		var propertyFilters []*model.PropertyFilter
+		for _, s := range filters {
+			filter, err := model.ParsePropertyFilter(s)
+			require.NoError(t, err)
+			propertyFilters = append(propertyFilters, filter)
+		}
+
+		rec, policy := newInputRecorder(nil)
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(newTestLoader(t, org, blue, red, green)),
+			infra.WithPolicy(policy),
+		),
+			usecase.WithPropertyFilters(propertyFilters),
+			// custom properties are retrieved for filters even if policy does not refer them
+			usecase.WithInputFields(model.InputFields{}),
+		)
+		if err := uc.Audit(types.NewContext(), "my-org"); err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for name := range rec.inputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+
+	t.Run("single value", func(t *testing.T) {
+		names, err := audit(t, org, "env=prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"blue"}, names)
+	})
+
+	t.Run("default value", func(t *testing.T) {
+		names, err := audit(t, org, "env=dev")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"green", "red"}, names)
+	})
+
+	t.Run("all filters must match", func(t *testing.T) {
+		names, err := audit(t, org, "tags=web", "env=dev")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"red"}, names)
+	})
+
+	t.Run("no repository matches", func(t *testing.T) {
+		names, err := audit(t, org, "env=staging")
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+
+	t.Run("custom properties are not available", func(t *testing.T) {
+		noSchema := &model.RegoOrgInput{Org: org.Org}
+		_, err := audit(t, noSchema, "env=prod")
+		assert.ErrorIs(t, err, types.ErrInvalidConfig)
+	})
+}
+
+func TestAuditExemption(t *testing.T) {
+	now := time.Now().UTC()
+	newInput := func(name, repoConfig string) *model.RegoInput {
+		input := &model.RegoInput{Repo: newTestRepo(name, now)}
+		if repoConfig != "" {
+			input.RepoConfig = model.ParseRepoConfig(model.RepoConfigPath, repoConfig, now)
+		}
+		return input
+	}
+
+	blue := newInput("blue", `exemptions:
+  - category: branch protection
+    message: main is not protected
+    reason: main is not used
+  - category: visibility
+    reason: public document
+    expires: "2999-12-31"
+`)
+	red := newInput("red", `exemptions:
+  - category: visibility
+    reason: temporary
+    expires: "2000-01-01"
+  - category: branch protection
+`)
+	green := newInput("green", "exemptions: [")
+	yellow := newInput("yellow", "")
+
+	_, policy := newInputRecorder(func(input *model.RegoInput) []*model.RegoFail {
+		return []*model.RegoFail{
+			{Category: "branch protection", Message: "main is not protected"},
+			{Category: "branch protection", Message: "release is not protected"},
+			{Category: "visibility", Message: "public"},
+		}
+	})
+	slack := &slackRecorder{}
+	uc := usecase.New(infra.New(
+		infra.WithGitHubApp(newTestLoader(t, nil, blue, red, green, yellow)),
+		infra.WithPolicy(policy),
+		infra.WithSlack(slack),
+	),
+		// ghaudit.yml is retrieved even if policy does not refer it
+		usecase.WithInputFields(model.InputFields{}),
+	)
+	require.ErrorIs(t, uc.Audit(types.NewContext(), "my-org"), types.ErrViolationDetected)
+
+	syntaxError := green.RepoConfig.Errors[0].Message
+	assert.Equal(t, map[string][]string{
+		"branch protection": {
+			"<https://github.com/my-org/blue|my-org/blue>: release is not protected",
+			"<https://github.com/my-org/green|my-org/green>: main is not protected",
+			"<https://github.com/my-org/green|my-org/green>: release is not protected",
+			"<https://github.com/my-org/red|my-org/red>: main is not protected",
+			"<https://github.com/my-org/red|my-org/red>: release is not protected",
+			"<https://github.com/my-org/yellow|my-org/yellow>: main is not protected",
+			"<https://github.com/my-org/yellow|my-org/yellow>: release is not protected",
+		},
+		"visibility": {
+			"<https://github.com/my-org/green|my-org/green>: public",
+			"<https://github.com/my-org/red|my-org/red>: public",
+			"<https://github.com/my-org/yellow|my-org/yellow>: public",
+		},
+		model.RepoConfigErrorCategory: {
+			"<https://github.com/my-org/green|my-org/green>: syntax_error: " + syntaxError,
+			"<https://github.com/my-org/red|my-org/red>: expired_exemption: exemption of 'visibility' expired at 2000-01-01",
+			"<https://github.com/my-org/red|my-org/red>: invalid_exemption: reason is required for exemption of 'branch protection'",
+		},
+	}, slack.violations)
+}
+
+func TestAuditFiles(t *testing.T) {
+	blue := &model.RegoInput{Repo: newTestRepo("blue", time.Now().UTC())}
+	readme := "# blue\n"
+
+	audit := func(t *testing.T, fileContent bool) map[string]*model.RegoInputFile {
+		client := newTestLoader(t, nil, blue)
+		client.files = map[string]string{"my-org/blue/README.md": readme}
+		rec, policy := newInputRecorder(nil)
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(client),
+			infra.WithPolicy(policy),
+		),
+			usecase.WithFiles([]string{"README.md", "SECURITY.md"}),
+			usecase.WithFileContent(fileContent),
+			usecase.WithInputFields(model.InputFields{"files": {}}),
+		)
+		require.NoError(t, uc.Audit(types.NewContext(), "my-org"))
+		require.Contains(t, rec.inputs, "blue")
+		return rec.inputs["blue"].Files
+	}
+
+	t.Run("content is not retrieved by default", func(t *testing.T) {
+		files := audit(t, false)
+		assert.Equal(t, map[string]*model.RegoInputFile{
+			"README.md": {
+				Exists: true,
+				Size:   len(readme),
+				SHA:    fmt.Sprintf("%x", sha1.Sum([]byte(readme))),
+			},
+			"SECURITY.md": {Exists: false},
+		}, files)
+	})
+
+	t.Run("content is decoded with file content option", func(t *testing.T) {
+		files := audit(t, true)
+		require.Contains(t, files, "README.md")
+		require.NotNil(t, files["README.md"].Content)
+		assert.Equal(t, readme, *files["README.md"].Content)
+		assert.True(t, files["README.md"].Exists)
+		assert.Equal(t, &model.RegoInputFile{Exists: false}, files["SECURITY.md"])
+	})
+}
+
+func TestAuditApps(t *testing.T) {
+	blue := &model.RegoInput{Repo: newTestRepo("blue", time.Now().UTC())}
+	org := &model.RegoOrgInput{
+		Org: &github.Organization{Login: github.String("my-org")},
+		Installations: []*model.AppInstallation{
+			{ID: 1, AppSlug: "all-app", RepositorySelection: "all"},
+			// repositories of selected installation are not available, then it is not attached to any repository
+			{ID: 2, AppSlug: "selected-app", RepositorySelection: "selected"},
+		},
+	}
+
+	rec, policy := newInputRecorder(nil)
+	uc := usecase.New(infra.New(
+		infra.WithGitHubApp(newTestLoader(t, org, blue)),
+		infra.WithPolicy(policy),
+	),
+		usecase.WithInputFields(model.InputFields{"apps": {}}),
+	)
+	require.NoError(t, uc.Audit(types.NewContext(), "my-org"))
+	require.Contains(t, rec.inputs, "blue")
+
+	var slugs []string
+	for _, app := range rec.inputs["blue"].Apps {
+		slugs = append(slugs, app.AppSlug)
+	}
+	assert.Equal(t, []string{"all-app"}, slugs)
+}
@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+	"github.com/m-mizutani/goerr"
+)
+
+const (
+	checkpointFileName = "checkpoint.json"
+	checkpointRepoDir  = "repos"
+)
+
+// checkpoint saves repository data and evaluation results in work directory progressively so that an interrupted audit can be resumed. nil checkpoint disables it.
+type checkpoint struct {
+	dir  string
+	meta checkpointMeta
+}
+
+type checkpointMeta struct {
+	Owner       string     `json:"owner"`
+	Settings    string     `json:"settings"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// checkpointEntry is a repository completed in the audit. Fails are results of repository policy after exemptions.
+type checkpointEntry struct {
+	Input               *model.RegoInput  `json:"input"`
+	DirectCollaborators []*github.User    `json:"direct_collaborators"`
+	Fails               []*model.RegoFail `json:"fails"`
+}
+
+// newCheckpoint starts a new checkpoint, or continues the previous one if resume is enabled and the previous audit was not completed.
+func (x *Usecase) newCheckpoint(owner string, startedAt time.Time) (*checkpoint, error) {
+	if x.workDir == "" {
+		return nil, nil
+	}
+
+	cp := &checkpoint{
+		dir: x.workDir,
+		meta: checkpointMeta{
+			Owner:     owner,
+			Settings:  x.checkpointSettings(),
+			StartedAt: startedAt,
+		},
+	}
+
+	if x.resume {
+		prev, err := loadCheckpointMeta(filepath.Join(x.workDir, checkpointFileName))
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case prev == nil:
+			utils.Logger.With("dir", x.workDir).Warn("no checkpoint to resume, starting new audit")
+		case prev.CompletedAt != nil:
+			utils.Logger.With("dir", x.workDir).Info("previous audit was completed, starting new audit")
+		case prev.Owner != cp.meta.Owner || prev.Settings != cp.meta.Settings:
+			return nil, goerr.Wrap(types.ErrInvalidConfig, "owner or options are different from the checkpoint to resume").
+				With("dir", x.workDir).
+				With("owner", prev.Owner)
+		default:
+			utils.Logger.With("dir", x.workDir).With("started_at", prev.StartedAt.Format(time.RFC3339)).Info("resuming audit from checkpoint")
+			cp.meta = *prev
+			return cp, nil
+		}
+	}
+
+	// remove only repository data of previous checkpoint
+	if err := os.RemoveAll(filepath.Join(x.workDir, checkpointRepoDir)); err != nil {
+		return nil, goerr.Wrap(err).With("dir", x.workDir)
+	}
+	if err := os.MkdirAll(filepath.Join(x.workDir, checkpointRepoDir), 0700); err != nil {
+		return nil, goerr.Wrap(err).With("dir", x.workDir)
+	}
+	if err := cp.writeMeta(); err != nil {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// checkpointSettings returns options that change repository data and results. Policy itself is not included.
+func (x *Usecase) checkpointSettings() string {
+	fields := "all"
+	if x.inputFields != nil {
+		fields = strings.Join(x.inputFields.Names(), ",")
+	}
+	return fmt.Sprintf("%s;fields=%s", x.settingsSignature(), fields)
+}
+
+// loadCheckpointMeta returns nil if the checkpoint does not exist or is broken.
+func loadCheckpointMeta(path string) (*checkpointMeta, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("path", path)
+	}
+
+	var meta checkpointMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		utils.Logger.With("path", path).With("error", err.Error()).Warn("ignore broken checkpoint")
+		return nil, nil
+	}
+	return &meta, nil
+}
+
+func (x *checkpoint) writeMeta() error {
+	raw, err := json.Marshal(&x.meta)
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	return writeFile(filepath.Join(x.dir, checkpointFileName), raw)
+}
+
+func (x *checkpoint) entryPath(repo *github.Repository) string {
+	return filepath.Join(x.dir, checkpointRepoDir, fmt.Sprintf("%s.json", repo.GetName()))
+}
+
+// load returns repository data and evaluation results completed before interruption. It returns nil if repo has not been completed.
+func (x *checkpoint) load(repo *github.Repository) *checkpointEntry {
+	if x == nil {
+		return nil
+	}
+
+	path := x.entryPath(repo)
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil
+	}
+	var entry checkpointEntry
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Input == nil {
+		// partially written entry by interruption
+		utils.Logger.With("path", path).Debug("ignore broken checkpoint entry")
+		return nil
+	}
+	entry.Input.DirectCollaborators = entry.DirectCollaborators
+	return &entry
+}
+
+// save stores repository data and evaluation results of a completed repository.
+func (x *checkpoint) save(input *model.RegoInput, records []*auditRecord) error {
+	if x == nil {
+		return nil
+	}
+
+	// organization data is retrieved again on resume
+	saved := *input
+	saved.Org = nil
+	entry := &checkpointEntry{
+		Input:               &saved,
+		DirectCollaborators: input.DirectCollaborators,
+		Fails:               []*model.RegoFail{},
+	}
+	for _, record := range records {
+		fail := record.RegoFail
+		entry.Fails = append(entry.Fails, &fail)
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	return writeFile(x.entryPath(input.Repo), raw)
+}
+
+// complete marks the checkpoint as completed. A completed checkpoint is not resumed.
+func (x *checkpoint) complete(now time.Time) error {
+	if x == nil {
+		return nil
+	}
+	x.meta.CompletedAt = &now
+	return x.writeMeta()
+}
+
+// records restores audit records of the entry for repo retrieved in current run, not one saved in the entry that may be outdated.
+func (x *checkpointEntry) records(repo *github.Repository) []*auditRecord {
+	var records []*auditRecord
+	for _, fail := range x.Fails {
+		records = append(records, &auditRecord{
+			RegoFail: *fail,
+			Repo:     repo,
+		})
+	}
+	return records
+}
+
+// writeFile writes data to a temporary file and renames it not to leave partial file by interruption.
+func writeFile(path string, raw []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return goerr.Wrap(err).With("path", path)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return goerr.Wrap(err).With("path", tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return goerr.Wrap(err).With("path", tmp.Name())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return goerr.Wrap(err).With("path", path)
+	}
+	return nil
+}
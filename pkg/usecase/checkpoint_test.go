@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra"
+	"github.com/m-mizutani/ghaudit/pkg/usecase"
+	"github.com/m-mizutani/opac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeAudit(t *testing.T) {
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	org := &model.RegoOrgInput{Org: &github.Organization{Login: github.String("my-org")}}
+	blue := &model.RegoInput{Repo: newTestRepo("blue", now)}
+	red := &model.RegoInput{Repo: newTestRepo("red", now)}
+	fail := func(input *model.RegoInput) []*model.RegoFail {
+		return []*model.RegoFail{{Category: "test", Message: input.Repo.GetName()}}
+	}
+
+	workDir := t.TempDir()
+	options := func(dumpDir string) []usecase.Option {
+		return []usecase.Option{
+			usecase.WithWorkDir(workDir),
+			usecase.WithResume(true),
+			usecase.WithConcurrency(1),
+			usecase.WithDump(dumpDir),
+		}
+	}
+
+	t.Run("audit is interrupted after blue is completed", func(t *testing.T) {
+		policy := opac.NewMock(func(in interface{}) (interface{}, error) {
+			input := in.(*model.RegoInput)
+			if input.Repo.GetName() == "red" {
+				return nil, errors.New("interrupted")
+			}
+			return &model.RegoOutput{Fail: fail(input)}, nil
+		})
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(newTestLoader(t, org, blue, red)),
+			infra.WithPolicy(policy),
+		), options(t.TempDir())...)
+
+		err := uc.Audit(types.NewContext(), "my-org")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, types.ErrViolationDetected)
+		assert.FileExists(t, filepath.Join(workDir, "repos", "blue.json"))
+	})
+
+	t.Run("resumed audit reports completed repositories with current repository data", func(t *testing.T) {
+		// repository data retrieved in resumed audit is newer than checkpoint
+		updated := *blue.Repo
+		updated.HTMLURL = github.String("https://github.example.com/my-org/blue")
+
+		client := newTestLoader(t, org, &model.RegoInput{Repo: &updated}, red)
+		client.noRetrieval = map[string]bool{"my-org/blue": true}
+		rec, policy := newInputRecorder(fail)
+		slack := &slackRecorder{}
+		dumpDir := t.TempDir()
+
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(client),
+			infra.WithPolicy(policy),
+			infra.WithSlack(slack),
+		), options(dumpDir)...)
+		require.ErrorIs(t, uc.Audit(types.NewContext(), "my-org"), types.ErrViolationDetected)
+
+		assert.NotContains(t, rec.inputs, "blue")
+		assert.Contains(t, rec.inputs, "red")
+		assert.Equal(t, []string{
+			"<https://github.com/my-org/red|my-org/red>: red",
+			"<https://github.example.com/my-org/blue|my-org/blue>: blue",
+		}, slack.violations["test"])
+
+		// completed repositories are also dumped with current repository data
+		raw, err := os.ReadFile(filepath.Join(dumpDir, "blue.json"))
+		require.NoError(t, err)
+		var dumped model.RegoInput
+		require.NoError(t, json.Unmarshal(raw, &dumped))
+		assert.Equal(t, "https://github.example.com/my-org/blue", dumped.Repo.GetHTMLURL())
+		assert.FileExists(t, filepath.Join(dumpDir, "red.json"))
+
+		for _, pattern := range []string{"tmp-*", "repos/tmp-*"} {
+			tmpFiles, err := filepath.Glob(filepath.Join(workDir, pattern))
+			require.NoError(t, err)
+			assert.Empty(t, tmpFiles)
+		}
+	})
+}
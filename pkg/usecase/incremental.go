@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+	"github.com/m-mizutani/goerr"
+)
+
+const (
+	stateFileName = "state.json"
+	stateInputDir = "inputs"
+)
+
+// incremental reuses repository data collected by previous audit for repositories not pushed or updated since then. Only data depending on repository content is reused, and other data (e.g. branch protection, collaborators and alerts) is retrieved every audit because it can be changed without push. nil incremental disables reuse.
+type incremental struct {
+	dir    string
+	prev   *model.AuditState
+	fields model.InputFields
+
+	mutex  sync.Mutex
+	next   *model.AuditState
+	saved  map[string]struct{}
+	reused int
+}
+
+// incrementalInput is repository data saved for next audit.
+type incrementalInput struct {
+	Input *model.RegoInput `json:"input"`
+}
+
+// newIncremental loads state of previous audit from state directory. The state is discarded if full refresh is required.
+func (x *Usecase) newIncremental(owner string, now time.Time) (*incremental, error) {
+	if x.stateDir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Join(x.stateDir, stateInputDir), 0700); err != nil {
+		return nil, goerr.Wrap(err).With("dir", x.stateDir)
+	}
+
+	prev, err := loadAuditState(filepath.Join(x.stateDir, stateFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	settings := x.settingsSignature()
+	inc := &incremental{
+		dir:    x.stateDir,
+		fields: x.inputFields,
+		saved:  make(map[string]struct{}),
+	}
+
+	if x.fullRefresh || prev.NeedsFullRefresh(owner, settings, x.refreshAge, now) {
+		utils.Logger.With("dir", x.stateDir).Info("collecting all repository data (full refresh)")
+		inc.next = model.NewAuditState(owner, settings, now)
+	} else {
+		utils.Logger.With("dir", x.stateDir).With("full_refresh_at", prev.FullRefreshAt.Format(time.RFC3339)).Info("collecting only changed repository data")
+		inc.prev = prev
+		inc.next = model.NewAuditState(owner, settings, prev.FullRefreshAt)
+	}
+
+	return inc, nil
+}
+
+// settingsSignature returns options that change reused content data other than input fields.
+func (x *Usecase) settingsSignature() string {
+	return fmt.Sprintf("files=%s;file_content=%t", strings.Join(x.files, ","), x.fileContent)
+}
+
+// loadAuditState returns nil if the state file does not exist or is broken.
+func loadAuditState(path string) (*model.AuditState, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("path", path)
+	}
+
+	var state model.AuditState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		utils.Logger.With("path", path).With("error", err.Error()).Warn("ignore broken audit state")
+		return nil, nil
+	}
+	if state.Repos == nil {
+		state.Repos = make(map[string]*model.RepoState)
+	}
+	return &state, nil
+}
+
+func (x *incremental) inputPath(repo *github.Repository) string {
+	return filepath.Join(x.dir, stateInputDir, fmt.Sprintf("%s.json", repo.GetName()))
+}
+
+// load returns repository data of previous audit. It returns nil if repo content needs to be collected again.
+func (x *incremental) load(repo *github.Repository) *model.RegoInput {
+	if x == nil || x.prev.Changed(repo, x.fields) {
+		return nil
+	}
+
+	path := x.inputPath(repo)
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		utils.Logger.With("path", path).With("error", err.Error()).Debug("repository data of previous audit is not available")
+		return nil
+	}
+	var saved incrementalInput
+	if err := json.Unmarshal(raw, &saved); err != nil || saved.Input == nil {
+		utils.Logger.With("path", path).Warn("ignore broken repository data of previous audit")
+		return nil
+	}
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.next.Repos[repo.GetFullName()] = x.prev.Repos[repo.GetFullName()]
+	x.saved[path] = struct{}{}
+	x.reused++
+
+	return saved.Input
+}
+
+// save stores collected repository data for next audit.
+func (x *incremental) save(input *model.RegoInput, now time.Time) error {
+	if x == nil {
+		return nil
+	}
+
+	// only data depending on repository content is reused
+	raw, err := json.Marshal(&incrementalInput{
+		Input: &model.RegoInput{
+			Repo:         input.Repo,
+			RepoConfig:   input.RepoConfig,
+			Dependencies: input.Dependencies,
+			Files:        input.Files,
+			CodeOwners:   input.CodeOwners,
+		},
+	})
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	path := x.inputPath(input.Repo)
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		return goerr.Wrap(err).With("path", path)
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.next.Update(input.Repo, x.fields, now)
+	x.saved[path] = struct{}{}
+	return nil
+}
+
+// commit saves state of the audit. It should be called after the audit is completed.
+func (x *incremental) commit() error {
+	if x == nil {
+		return nil
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	raw, err := json.Marshal(x.next)
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	path := filepath.Join(x.dir, stateFileName)
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		return goerr.Wrap(err).With("path", path)
+	}
+
+	// repository data of deleted or filtered out repositories is never reused
+	paths, err := filepath.Glob(filepath.Join(x.dir, stateInputDir, "*.json"))
+	if err != nil {
+		return goerr.Wrap(err).With("dir", x.dir)
+	}
+	for _, path := range paths {
+		if _, ok := x.saved[path]; ok {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return goerr.Wrap(err).With("path", path)
+		}
+	}
+
+	utils.Logger.With("reused", x.reused).With("collected", len(x.next.Repos)-x.reused).Info("saved audit state")
+	return nil
+}
+
+// reuseContentData copies data depending only on repository content from repository data of previous audit. repo_config and CODEOWNERS are parsed again because expiration of exemptions depends on now and CODEOWNERS is validated with current collaborators and teams.
+func reuseContentData(input, reused *model.RegoInput, fields model.InputFields, now time.Time) {
+	if reused.RepoConfig != nil {
+		input.RepoConfig = model.ParseRepoConfig(reused.RepoConfig.Path, reused.RepoConfig.Content, now)
+	}
+	if fields.Has("dependencies") {
+		input.Dependencies = reused.Dependencies
+	}
+	if fields.Has("files") {
+		input.Files = reused.Files
+	}
+	if fields.Has("codeowners") && reused.CodeOwners != nil {
+		input.CodeOwners = model.ParseCodeOwners(reused.CodeOwners.Path, reused.CodeOwners.Content)
+	}
+}
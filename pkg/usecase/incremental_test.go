@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra"
+	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
+	"github.com/m-mizutani/ghaudit/pkg/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrementalAudit(t *testing.T) {
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	alice := github.User{ID: github.Int64(10), Login: github.String("alice")}
+	bob := github.User{ID: github.Int64(11), Login: github.String("bob")}
+
+	blue := &model.RegoInput{
+		Repo: newTestRepo("blue", now),
+		Collaborators: []*github.User{
+			{ID: alice.ID, Login: alice.Login, Permissions: model.PermissionToMap(model.PermissionWrite)},
+			{ID: bob.ID, Login: bob.Login, Permissions: model.PermissionToMap(model.PermissionWrite)},
+		},
+		Teams: []*model.RegoInputTeam{
+			{Team: github.Team{ID: github.Int64(100), Slug: github.String("eng"), Permission: github.String("push")}},
+		},
+		// direct collaborators are restored from access by loader client
+		Access: []*model.RegoInputAccess{
+			{
+				Type:  model.AccessTypeUser,
+				Login: bob.GetLogin(),
+				ID:    bob.GetID(),
+				Grants: []*model.RegoInputAccessGrant{
+					{Via: model.AccessViaDirect, Permission: model.PermissionWrite},
+				},
+			},
+		},
+		CodeOwners: model.ParseCodeOwners(".github/CODEOWNERS", "* @my-org/eng @my-org/ops\n"),
+	}
+	red := &model.RegoInput{Repo: newTestRepo("red", now)}
+
+	newOrg := func(engMembers []*github.User, teams ...*model.RegoInputTeam) *model.RegoOrgInput {
+		return &model.RegoOrgInput{
+			Org: &github.Organization{Login: github.String("my-org")},
+			Members: []*model.RegoInputOrgMember{
+				{User: alice, Role: "member"},
+				{User: bob, Role: "member"},
+			},
+			Teams: append([]*model.RegoInputTeam{
+				{Team: github.Team{ID: github.Int64(100), Slug: github.String("eng")}, Members: engMembers},
+			}, teams...),
+		}
+	}
+
+	stateDir := t.TempDir()
+	audit := func(t *testing.T, client githubapp.Client) *model.RegoInput {
+		rec, policy := newInputRecorder(nil)
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(client),
+			infra.WithPolicy(policy),
+		),
+			usecase.WithStateDir(stateDir),
+			usecase.WithInputFields(model.InputFields{"access": {}, "codeowners": {}}),
+		)
+		require.NoError(t, uc.Audit(types.NewContext(), "my-org"))
+		require.Contains(t, rec.inputs, "blue")
+		return rec.inputs["blue"]
+	}
+
+	grants := func(input *model.RegoInput, login string) []string {
+		var vias []string
+		for _, access := range input.Access {
+			if access.Type == model.AccessTypeUser && access.Login == login {
+				for _, grant := range access.Grants {
+					vias = append(vias, grant.Via)
+				}
+			}
+		}
+		return vias
+	}
+	ownerError := func(input *model.RegoInput, owner string) string {
+		for _, e := range input.CodeOwners.Errors {
+			if e.Owner == owner {
+				return e.Kind
+			}
+		}
+		return ""
+	}
+
+	t.Run("first audit collects all repositories", func(t *testing.T) {
+		input := audit(t, newTestLoader(t, newOrg([]*github.User{&alice}), blue, red))
+
+		assert.Equal(t, []string{model.AccessViaTeam}, grants(input, "alice"))
+		assert.Equal(t, []string{model.AccessViaDirect}, grants(input, "bob"))
+		assert.Equal(t, model.CodeOwnersUnknownTeam, ownerError(input, "@my-org/ops"))
+		assert.FileExists(t, filepath.Join(stateDir, "inputs", "blue.json"))
+		assert.FileExists(t, filepath.Join(stateDir, "inputs", "red.json"))
+	})
+
+	t.Run("content data is reused and other data is retrieved again", func(t *testing.T) {
+		// alice left eng and ops team was created without access to the repository
+		org := newOrg(nil, &model.RegoInputTeam{
+			Team: github.Team{ID: github.Int64(101), Slug: github.String("ops")},
+		})
+		// bob was removed from collaborators without push to the repository
+		updated := *blue
+		updated.Collaborators = blue.Collaborators[:1]
+		updated.Access = nil
+		client := newTestLoader(t, org, &updated)
+		client.noRetrieval = map[string]bool{"my-org/blue": true}
+		input := audit(t, client)
+
+		assert.Equal(t, []string{model.AccessViaUnknown}, grants(input, "alice"))
+		assert.Empty(t, grants(input, "bob"))
+		assert.Equal(t, model.CodeOwnersTeamWithoutAccess, ownerError(input, "@my-org/ops"))
+
+		// red was deleted
+		assert.FileExists(t, filepath.Join(stateDir, "inputs", "blue.json"))
+		_, err := os.Stat(filepath.Join(stateDir, "inputs", "red.json"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -62,7 +62,7 @@ func (x *auditResult) buildViolationSlackBlocks() []slack.Block {
 		lines := []string{fmt.Sprintf("Policy: *%s*", cat)}
 		for i := 0; i < listLimit && i < len(records); i++ {
 			r := records[i]
-			msg := fmt.Sprintf("- <%s|%s>", r.Repo.GetHTMLURL(), r.Repo.GetFullName())
+			msg := fmt.Sprintf("- <%s|%s>", r.targetURL(), r.targetName())
 			if r.Message != "" {
 				msg += ": " + r.Message
 			}
@@ -71,7 +71,7 @@ func (x *auditResult) buildViolationSlackBlocks() []slack.Block {
 		}
 		if more := len(records) - listLimit; more > 0 {
 			lines = append(lines, "")
-			lines = append(lines, fmt.Sprintf("and more %d repos", more))
+			lines = append(lines, fmt.Sprintf("and more %d targets", more))
 		}
 
 		blocks = append(blocks, []slack.Block{
@@ -141,7 +141,7 @@ func (x *Usecase) output(ctx *types.Context, result *auditResult) error {
 		for category, records := range result.Records {
 			fmt.Printf("[%s]\n", category)
 			for _, record := range records {
-				fmt.Printf("- %s: %s\n", record.Repo.GetFullName(), record.Message)
+				fmt.Printf("- %s: %s\n", record.targetName(), record.Message)
 			}
 		}
 		fmt.Printf("\n")
@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
+	"github.com/m-mizutani/ghaudit/pkg/utils"
+	"github.com/m-mizutani/goerr"
+	"github.com/m-mizutani/opac"
+)
+
+// orgInputFields returns fields of RegoOrgInput to be retrieved. nil means all fields, and empty fields means organization data is not required. All fields are required by organization policy, aggregate policy and repository policy referring input.org. Otherwise only fields used to build other repository data are retrieved.
+func (x *Usecase) orgInputFields() model.InputFields {
+	if x.clients.OrgPolicy() != nil || x.clients.AggregatePolicy() != nil || x.inputFields.Has("org") {
+		return nil
+	}
+
+	fields := model.InputFields{}
+	add := func(repoField string, orgFields ...string) {
+		if x.inputFields.Has(repoField) {
+			for _, f := range orgFields {
+				fields[f] = struct{}{}
+			}
+		}
+	}
+	add("apps", "installations")
+	add("properties", "property_schema")
+	add("teams", "teams")
+	add("codeowners", "teams")
+	add("access", "teams", "members")
+	add("runners", "runner_groups")
+	if len(x.propertyFilters) > 0 {
+		fields["property_schema"] = struct{}{}
+	}
+
+	return fields
+}
+
+// createRegoOrgInput retrieves fields of organization data. It returns nil if no field is required or owner is not an accessible organization.
+func createRegoOrgInput(ctx *types.Context, client githubapp.Client, owner string, fields model.InputFields) (*model.RegoOrgInput, error) {
+	if !fields.Any() {
+		utils.Logger.With("org", owner).Debug("organization data is not required")
+		return nil, nil
+	}
+	utils.Logger.With("org", owner).With("fields", fields.Names()).Trace("retrieving organization data")
+
+	org, err := client.GetOrganization(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+	if org == nil {
+		return nil, nil
+	}
+
+	input := &model.RegoOrgInput{
+		Org:       org,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+
+	if fields.Has("hooks") {
+		if input.Hooks, err = getOrgHooks(ctx, client, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.Has("actions_permissions") {
+		if input.ActionsPermissions, err = client.GetOrgActionsPermissions(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("actions_allowed") {
+		if input.ActionsAllowed, err = client.GetOrgActionsAllowed(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("saml_sso_enabled") {
+		if input.SAMLSSOEnabled, err = client.GetOrgSAMLSSOEnabled(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("members") {
+		if input.Members, err = getOrgMembers(ctx, client, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.Has("outside_collaborators") {
+		if input.OutsideCollaborators, err = client.GetOutsideCollaborators(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("invitations") {
+		if input.Invitations, err = client.GetOrgInvitations(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("teams") {
+		if input.Teams, err = getOrgTeams(ctx, client, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.Has("installations") {
+		if input.Installations, err = client.GetOrgInstallations(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("runners") {
+		if input.Runners, err = client.GetOrgRunners(ctx, owner); err != nil {
+			return nil, goerr.Wrap(err)
+		}
+	}
+
+	if fields.Has("runner_groups") {
+		if input.RunnerGroups, err = getOrgRunnerGroups(ctx, client, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.Has("property_schema") {
+		if input.PropertySchema, input.PropertyValues, err = getOrgProperties(ctx, client, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	utils.Logger.With("org", owner).Trace("created organization input")
+
+	return input, nil
+}
+
+func getOrgHooks(ctx *types.Context, client githubapp.Client, owner string) ([]*model.RegoInputHook, error) {
+	githubHooks, err := client.GetOrgHooks(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var hooks []*model.RegoInputHook
+	for _, hook := range githubHooks {
+		deliveries, err := client.GetOrgHookDeliveries(ctx, owner, hook.GetID())
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		hooks = append(hooks, model.NewRegoInputHook(hook, deliveries))
+	}
+	return hooks, nil
+}
+
+func getOrgMembers(ctx *types.Context, client githubapp.Client, owner string) ([]*model.RegoInputOrgMember, error) {
+	var members []*model.RegoInputOrgMember
+	for _, role := range []string{"admin", "member"} {
+		users, err := client.GetOrgMembers(ctx, owner, role)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		for _, user := range users {
+			members = append(members, &model.RegoInputOrgMember{
+				User: *user,
+				Role: role,
+			})
+		}
+	}
+
+	without2FA, err := client.GetOrgMembersWithout2FA(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+	if without2FA != nil {
+		disabled := make(map[int64]bool)
+		for _, user := range without2FA {
+			disabled[user.GetID()] = true
+		}
+		for _, member := range members {
+			v := disabled[member.GetID()]
+			member.TwoFactorDisabled = &v
+		}
+	}
+
+	return members, nil
+}
+
+// getOrgTeams retrieves all teams and their members once per run. Members of each team are attached to input.teams of repositories.
+func getOrgTeams(ctx *types.Context, client githubapp.Client, owner string) ([]*model.RegoInputTeam, error) {
+	githubTeams, err := client.GetOrgTeams(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var teams []*model.RegoInputTeam
+	for _, team := range githubTeams {
+		members, err := client.GetTeamMembers(ctx, owner, team.GetSlug(), "all")
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		maintainers, err := client.GetTeamMembers(ctx, owner, team.GetSlug(), "maintainer")
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+
+		teams = append(teams, &model.RegoInputTeam{
+			Team:        *team,
+			Members:     members,
+			Maintainers: maintainers,
+		})
+	}
+	utils.Logger.With("teams", len(teams)).Trace("retrieved organization teams")
+
+	return teams, nil
+}
+
+// getOrgProperties retrieves custom property schema and values of all repositories at once. Values are not retrieved if the schema is not available.
+func getOrgProperties(ctx *types.Context, client githubapp.Client, owner string) ([]*model.CustomProperty, map[string]*model.RepoCustomPropertyValues, error) {
+	schema, err := client.GetOrgPropertySchema(ctx, owner)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err)
+	}
+	if schema == nil {
+		return nil, nil, nil
+	}
+
+	githubValues, err := client.GetOrgPropertyValues(ctx, owner)
+	if err != nil {
+		return nil, nil, goerr.Wrap(err)
+	}
+	values := make(map[string]*model.RepoCustomPropertyValues)
+	for _, v := range githubValues {
+		values[v.RepositoryFullName] = v
+	}
+
+	return schema, values, nil
+}
+
+// getOrgRunnerGroups retrieves runner groups with their runners. Repositories that can use a group are retrieved only if visibility of the group is "selected".
+func getOrgRunnerGroups(ctx *types.Context, client githubapp.Client, owner string) ([]*model.RegoInputRunnerGroup, error) {
+	githubGroups, err := client.GetOrgRunnerGroups(ctx, owner)
+	if err != nil {
+		return nil, goerr.Wrap(err)
+	}
+
+	var groups []*model.RegoInputRunnerGroup
+	for _, group := range githubGroups {
+		runners, err := client.GetRunnerGroupRunners(ctx, owner, group.ID)
+		if err != nil {
+			return nil, goerr.Wrap(err)
+		}
+		g := &model.RegoInputRunnerGroup{
+			RunnerGroup: *group,
+			Runners:     runners,
+		}
+
+		if group.Visibility == model.RunnerGroupVisibilitySelected {
+			repos, err := client.GetRunnerGroupRepos(ctx, owner, group.ID)
+			if err != nil {
+				return nil, goerr.Wrap(err)
+			}
+			g.Repositories = []string{}
+			for _, repo := range repos {
+				g.Repositories = append(g.Repositories, repo.GetFullName())
+			}
+		}
+
+		groups = append(groups, g)
+	}
+	utils.Logger.With("runner groups", len(groups)).Trace("retrieved runner groups")
+
+	return groups, nil
+}
+
+func (x *Usecase) dumpOrgInput(input *model.RegoOrgInput) error {
+	dir := filepath.Join(x.dumpDir, types.DumpOrgDir)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return goerr.Wrap(err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s.json", input.Org.GetLogin()))
+	fd, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return goerr.Wrap(err)
+	}
+	defer fd.Close()
+	if err := json.NewEncoder(fd).Encode(input); err != nil {
+		return goerr.Wrap(err)
+	}
+
+	return nil
+}
+
+func (x *Usecase) evaluateOrg(ctx *types.Context, input *model.RegoOrgInput) ([]*auditRecord, error) {
+	var output model.RegoOutput
+	orgName := input.Org.GetLogin()
+	utils.Logger.With("org", orgName).Trace("evaluating organization data")
+	if err := x.clients.OrgPolicy().Query(ctx, input, &output); err != nil {
+		if errors.Is(err, opac.ErrNoEvalResult) {
+			utils.Logger.With("org", orgName).Debug("no organization policy")
+			return nil, nil
+		}
+		return nil, goerr.Wrap(err).With("org", orgName)
+	}
+
+	var results []*auditRecord
+	for _, fail := range output.Fail {
+		results = append(results, &auditRecord{
+			RegoFail: *fail,
+			Org:      input.Org,
+		})
+	}
+
+	return results, nil
+}
+
+// auditOrg evaluates organization data with organization policy. It does nothing if organization policy is not configured or organization data is not available.
+func (x *Usecase) auditOrg(ctx *types.Context, input *model.RegoOrgInput) ([]*auditRecord, error) {
+	if x.clients.OrgPolicy() == nil || input == nil {
+		return nil, nil
+	}
+
+	return x.evaluateOrg(ctx, input)
+}
@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra"
+	"github.com/m-mizutani/ghaudit/pkg/usecase"
+	"github.com/m-mizutani/opac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrgPolicy = `package github.org
+
+fail[res] {
+	m := input.members[_]
+	m["2fa_disabled"]
+	res := {"category": "2FA must be enabled", "message": m.login}
+}
+
+fail[res] {
+	owners := [m | m := input.members[_]; m.role == "admin"]
+	count(owners) < 2
+	res := {"category": "organization must have 2 owners at least", "message": sprintf("%d owner", [count(owners)])}
+}
+
+fail[res] {
+	c := input.outside_collaborators[_]
+	res := {"category": "outside collaborator is not allowed", "message": c.login}
+}
+
+fail[res] {
+	t := input.teams[_]
+	not has_members(t)
+	res := {"category": "team must have members", "message": t.slug}
+}
+
+has_members(t) {
+	count(t.members) > 0
+}
+
+fail[res] {
+	input.saml_sso_enabled == false
+	res := {"category": "SAML SSO must be enabled", "message": ""}
+}
+`
+
+func TestAuditOrg(t *testing.T) {
+	alice := github.User{ID: github.Int64(10), Login: github.String("alice")}
+	bob := github.User{ID: github.Int64(11), Login: github.String("bob")}
+	carol := &github.User{ID: github.Int64(12), Login: github.String("carol")}
+
+	org := &model.RegoOrgInput{
+		Org: &github.Organization{
+			Login:   github.String("my-org"),
+			HTMLURL: github.String("https://github.com/my-org"),
+		},
+		Members: []*model.RegoInputOrgMember{
+			{User: alice, Role: "admin", TwoFactorDisabled: github.Bool(false)},
+			{User: bob, Role: "member", TwoFactorDisabled: github.Bool(true)},
+		},
+		OutsideCollaborators: []*github.User{carol},
+		Teams: []*model.RegoInputTeam{
+			{Team: github.Team{ID: github.Int64(100), Slug: github.String("eng")}, Members: []*github.User{&alice, &bob}},
+			{Team: github.Team{ID: github.Int64(101), Slug: github.String("ops")}},
+		},
+	}
+	blue := &model.RegoInput{Repo: newTestRepo("blue", time.Now().UTC())}
+
+	orgPolicy, err := opac.NewLocal(
+		opac.WithPolicyData("org.rego", testOrgPolicy),
+		opac.WithPackage("github.org"),
+	)
+	require.NoError(t, err)
+
+	t.Run("organization data is evaluated by organization policy", func(t *testing.T) {
+		_, policy := newInputRecorder(func(input *model.RegoInput) []*model.RegoFail {
+			return []*model.RegoFail{{Category: "repository", Message: "test"}}
+		})
+		slack := &slackRecorder{}
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(newTestLoader(t, org, blue)),
+			infra.WithPolicy(policy),
+			infra.WithOrgPolicy(orgPolicy),
+			infra.WithSlack(slack),
+		))
+		require.ErrorIs(t, uc.Audit(types.NewContext(), "my-org"), types.ErrViolationDetected)
+
+		// SAML SSO status is not available in test data and is not regarded as disabled
+		assert.Equal(t, map[string][]string{
+			"2FA must be enabled":                      {"<https://github.com/my-org|my-org>: bob"},
+			"organization must have 2 owners at least": {"<https://github.com/my-org|my-org>: 1 owner"},
+			"outside collaborator is not allowed":      {"<https://github.com/my-org|my-org>: carol"},
+			"team must have members":                   {"<https://github.com/my-org|my-org>: ops"},
+			"repository":                               {"<https://github.com/my-org/blue|my-org/blue>: test"},
+		}, slack.violations)
+	})
+
+	t.Run("organization policy is not evaluated without organization data", func(t *testing.T) {
+		_, policy := newInputRecorder(nil)
+		slack := &slackRecorder{}
+		uc := usecase.New(infra.New(
+			infra.WithGitHubApp(newTestLoader(t, nil, blue)),
+			infra.WithPolicy(policy),
+			infra.WithOrgPolicy(orgPolicy),
+			infra.WithSlack(slack),
+		))
+		require.NoError(t, uc.Audit(types.NewContext(), "my-org"))
+		assert.Empty(t, slack.violations)
+	})
+}
@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v42/github"
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
+	"github.com/m-mizutani/ghaudit/pkg/domain/types"
+	"github.com/m-mizutani/ghaudit/pkg/infra/githubapp"
+	"github.com/m-mizutani/opac"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestData writes organization and repository data in dump format so that it can be loaded by loader client.
+func writeTestData(t *testing.T, org *model.RegoOrgInput, inputs ...*model.RegoInput) string {
+	dir := t.TempDir()
+	write := func(path string, v interface{}) {
+		raw, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, raw, 0600))
+	}
+
+	for _, input := range inputs {
+		write(filepath.Join(dir, input.Repo.GetName()+".json"), input)
+	}
+	if org != nil {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, types.DumpOrgDir), 0700))
+		write(filepath.Join(dir, types.DumpOrgDir, org.Org.GetLogin()+".json"), org)
+	}
+	return dir
+}
+
+func newTestLoader(t *testing.T, org *model.RegoOrgInput, inputs ...*model.RegoInput) *testClient {
+	client, err := githubapp.NewloaderClient(writeTestData(t, org, inputs...))
+	require.NoError(t, err)
+	return &testClient{Client: client}
+}
+
+func newTestRepo(name string, pushedAt time.Time) *github.Repository {
+	return &github.Repository{
+		ID:        github.Int64(int64(len(name))),
+		Name:      github.String(name),
+		FullName:  github.String("my-org/" + name),
+		HTMLURL:   github.String("https://github.com/my-org/" + name),
+		Owner:     &github.User{Login: github.String("my-org")},
+		PushedAt:  &github.Timestamp{Time: pushedAt},
+		UpdatedAt: &github.Timestamp{Time: pushedAt},
+	}
+}
+
+// testClient is a GitHub App client loading test data. It returns repositories sorted by name, and fails to retrieve files of repositories in noRetrieval to make sure that their content data is not retrieved. Files in files (keyed by "owner/repo/path") are returned in base64 encoding as GitHub API does.
+type testClient struct {
+	githubapp.Client
+	noRetrieval map[string]bool
+	files       map[string]string
+}
+
+func (x *testClient) GetRepos(ctx *types.Context, owner string) ([]*github.Repository, error) {
+	repos, err := x.Client.GetRepos(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].GetName() < repos[j].GetName()
+	})
+	return repos, nil
+}
+
+func (x *testClient) GetFile(ctx *types.Context, owner, repo, path string) (*github.RepositoryContent, error) {
+	if x.noRetrieval[owner+"/"+repo] {
+		return nil, fmt.Errorf("%s/%s should not be retrieved", owner, repo)
+	}
+	if content, ok := x.files[owner+"/"+repo+"/"+path]; ok {
+		return &github.RepositoryContent{
+			Type:     github.String("file"),
+			Path:     github.String(path),
+			Size:     github.Int(len(content)),
+			SHA:      github.String(fmt.Sprintf("%x", sha1.Sum([]byte(content)))),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		}, nil
+	}
+	return x.Client.GetFile(ctx, owner, repo, path)
+}
+
+// inputRecorder is a repository policy that records evaluated inputs by repository name and returns fails of failFunc.
+type inputRecorder struct {
+	inputs   map[string]*model.RegoInput
+	failFunc func(input *model.RegoInput) []*model.RegoFail
+}
+
+func newInputRecorder(failFunc func(input *model.RegoInput) []*model.RegoFail) (*inputRecorder, opac.Client) {
+	rec := &inputRecorder{
+		inputs:   make(map[string]*model.RegoInput),
+		failFunc: failFunc,
+	}
+	return rec, opac.NewMock(func(in interface{}) (interface{}, error) {
+		input := in.(*model.RegoInput)
+		rec.inputs[input.Repo.GetName()] = input
+
+		output := &model.RegoOutput{}
+		if rec.failFunc != nil {
+			output.Fail = rec.failFunc(input)
+		}
+		return output, nil
+	})
+}
+
+// slackRecorder is a Slack client that records violations of the last notification by category. A violation is "<url|name>" followed by message if any.
+type slackRecorder struct {
+	violations map[string][]string
+}
+
+func (x *slackRecorder) Post(ctx *types.Context, msg *slack.WebhookMessage) error {
+	x.violations = make(map[string][]string)
+	for _, attachment := range msg.Attachments {
+		for _, block := range attachment.Blocks.BlockSet {
+			section, ok := block.(*slack.SectionBlock)
+			if !ok || section.Text == nil {
+				continue
+			}
+			lines := strings.Split(section.Text.Text, "\n")
+			if !strings.HasPrefix(lines[0], "Policy: *") {
+				continue
+			}
+			category := strings.TrimSuffix(strings.TrimPrefix(lines[0], "Policy: *"), "*")
+			for _, line := range lines[1:] {
+				if strings.HasPrefix(line, "- ") {
+					x.violations[category] = append(x.violations[category], strings.TrimPrefix(line, "- "))
+				}
+			}
+			sort.Strings(x.violations[category])
+		}
+	}
+	return nil
+}
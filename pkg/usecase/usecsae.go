@@ -2,37 +2,60 @@ package usecase
 
 import (
 	"path/filepath"
+	"time"
 
+	"github.com/m-mizutani/ghaudit/pkg/domain/model"
 	"github.com/m-mizutani/ghaudit/pkg/infra"
 )
 
 type Usecase struct {
-	clients *infra.Clients
-	thread  int64
-	limit   int64
-	dumpDir string
+	clients     *infra.Clients
+	concurrency int64
+	limit       int64
+	dumpDir     string
 
 	skipArchived bool
+
+	files       []string
+	fileContent bool
+	commits     int64
+
+	propertyFilters []*model.PropertyFilter
+	inputFields     model.InputFields
+
+	stateDir    string
+	fullRefresh bool
+	refreshAge  time.Duration
+
+	workDir string
+	resume  bool
 }
 
 func New(clients *infra.Clients, options ...Option) *Usecase {
 	uc := &Usecase{
-		clients: clients,
-		thread:  4,
+		clients:     clients,
+		concurrency: 4,
+		commits:     100,
 	}
 
 	for _, opt := range options {
 		opt(uc)
 	}
 
+	// dumped data should be available for any policy
+	if uc.dumpDir != "" {
+		uc.inputFields = nil
+	}
+
 	return uc
 }
 
 type Option func(uc *Usecase)
 
-func WithThread(n int64) Option {
+// WithConcurrency sets number of repositories retrieved concurrently. Actual number of concurrent API requests is limited by GitHub API client.
+func WithConcurrency(n int64) Option {
 	return func(uc *Usecase) {
-		uc.thread = n
+		uc.concurrency = n
 	}
 }
 
@@ -53,3 +76,71 @@ func WithSkipArchived(skip bool) Option {
 		uc.skipArchived = skip
 	}
 }
+
+func WithFiles(paths []string) Option {
+	return func(uc *Usecase) {
+		uc.files = paths
+	}
+}
+
+func WithFileContent(enable bool) Option {
+	return func(uc *Usecase) {
+		uc.fileContent = enable
+	}
+}
+
+// WithCommits sets number of recent commits of default branch to calculate signature statistics. Zero disables the statistics.
+func WithCommits(n int64) Option {
+	return func(uc *Usecase) {
+		uc.commits = n
+	}
+}
+
+// WithPropertyFilters audits only repositories having all custom property values of filters.
+func WithPropertyFilters(filters []*model.PropertyFilter) Option {
+	return func(uc *Usecase) {
+		uc.propertyFilters = filters
+	}
+}
+
+// WithInputFields retrieves only repository data of fields referred by policies. nil fields (default) retrieves all data. It is ignored if dump is enabled.
+func WithInputFields(fields model.InputFields) Option {
+	return func(uc *Usecase) {
+		uc.inputFields = fields
+	}
+}
+
+// WithStateDir enables incremental audit. Repository data and state of audit are saved in dir, and repositories not changed since previous audit are not retrieved again.
+func WithStateDir(dir string) Option {
+	return func(uc *Usecase) {
+		uc.stateDir = filepath.Clean(dir)
+	}
+}
+
+// WithFullRefresh retrieves all repositories even if incremental audit is enabled.
+func WithFullRefresh(enable bool) Option {
+	return func(uc *Usecase) {
+		uc.fullRefresh = enable
+	}
+}
+
+// WithRefreshAge sets maximum age of the last full refresh in incremental audit. Zero never forces full refresh.
+func WithRefreshAge(d time.Duration) Option {
+	return func(uc *Usecase) {
+		uc.refreshAge = d
+	}
+}
+
+// WithWorkDir saves repository data and evaluation results in dir progressively as checkpoint.
+func WithWorkDir(dir string) Option {
+	return func(uc *Usecase) {
+		uc.workDir = filepath.Clean(dir)
+	}
+}
+
+// WithResume continues interrupted audit from checkpoint in work directory. Repositories completed before interruption are not retrieved and evaluated again.
+func WithResume(enable bool) Option {
+	return func(uc *Usecase) {
+		uc.resume = enable
+	}
+}